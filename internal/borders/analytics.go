@@ -0,0 +1,120 @@
+package borders
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+)
+
+// Codes returns the sorted alpha-3 codes of every country in the graph.
+func (g *Graph) Codes() []string {
+	codes := make([]string, 0, len(g.countries))
+	for code := range g.countries {
+		codes = append(codes, code)
+	}
+	slices.Sort(codes)
+	return codes
+}
+
+// Components returns the connected components of the graph, the landmasses
+// reachable overland, each as sorted alpha-3 codes. Larger components come
+// first; components of equal size are ordered by their first code. Countries
+// without land borders form components of their own.
+func (g *Graph) Components() [][]string {
+	seen := make(map[string]bool, len(g.countries))
+	var components [][]string
+	for _, code := range g.Codes() {
+		if seen[code] {
+			continue
+		}
+		seen[code] = true
+		component := []string{code}
+		for i := 0; i < len(component); i++ {
+			for _, n := range g.adjacent[component[i]] {
+				if !seen[n] {
+					seen[n] = true
+					component = append(component, n)
+				}
+			}
+		}
+		slices.Sort(component)
+		components = append(components, component)
+	}
+	slices.SortStableFunc(components, func(a, b []string) int {
+		return cmp.Compare(len(b), len(a))
+	})
+	return components
+}
+
+// Enclaves returns the sorted codes of landlocked countries entirely
+// surrounded by a single other country, such as Lesotho.
+func (g *Graph) Enclaves() []string {
+	var enclaves []string
+	for _, code := range g.Codes() {
+		if g.countries[code].Landlocked && len(g.adjacent[code]) == 1 {
+			enclaves = append(enclaves, code)
+		}
+	}
+	return enclaves
+}
+
+// DoublyLandlocked returns the sorted codes of landlocked countries whose
+// neighbours are all landlocked too, such as Liechtenstein.
+func (g *Graph) DoublyLandlocked() []string {
+	var codes []string
+	for _, code := range g.Codes() {
+		neighbours := g.adjacent[code]
+		if !g.countries[code].Landlocked || len(neighbours) == 0 {
+			continue
+		}
+		if !slices.ContainsFunc(neighbours, func(n string) bool { return !g.countries[n].Landlocked }) {
+			codes = append(codes, code)
+		}
+	}
+	return codes
+}
+
+// Ranking returns every country's code ordered by number of neighbours, most
+// first, ties broken by code.
+func (g *Graph) Ranking() []string {
+	codes := g.Codes()
+	slices.SortStableFunc(codes, func(a, b string) int {
+		return cmp.Compare(len(g.adjacent[b]), len(g.adjacent[a]))
+	})
+	return codes
+}
+
+// Asymmetry is a border listed by one country but not by the other.
+type Asymmetry struct {
+	// Country lists Border among its borders; Border does not list Country.
+	Country string
+	Border  string
+}
+
+// Asymmetries returns the borders listed by only one of the two countries,
+// ordered by the listing country and then the border. The graph treats these
+// as borders in both directions.
+func (g *Graph) Asymmetries() []Asymmetry {
+	var asymmetries []Asymmetry
+	for _, code := range g.Codes() {
+		for _, border := range g.listed(code) {
+			if _, ok := g.countries[border]; !ok {
+				continue
+			}
+			if !slices.Contains(g.listed(border), code) {
+				asymmetries = append(asymmetries, Asymmetry{Country: code, Border: border})
+			}
+		}
+	}
+	return asymmetries
+}
+
+// listed returns the sorted, upper-case border codes code lists upstream.
+func (g *Graph) listed(code string) []string {
+	var listed []string
+	for _, border := range g.countries[code].Borders {
+		listed = append(listed, strings.ToUpper(border))
+	}
+	slices.Sort(listed)
+	return slices.Compact(listed)
+}
@@ -0,0 +1,70 @@
+package borders
+
+import (
+	"countryinfo/internal/restclient"
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+// analyticsCountries covers an enclave (Lesotho), a doubly landlocked country
+// (Liechtenstein), an island and one asymmetric border: Germany lists
+// Switzerland, which does not list Germany back.
+const analyticsCountries = `[
+	{"cca3":"ZAF","borders":["LSO"]},
+	{"cca3":"LSO","landlocked":true,"borders":["ZAF"]},
+	{"cca3":"CHE","landlocked":true,"borders":["AUT","LIE"]},
+	{"cca3":"AUT","landlocked":true,"borders":["CHE","DEU","LIE"]},
+	{"cca3":"LIE","landlocked":true,"borders":["AUT","CHE"]},
+	{"cca3":"DEU","borders":["AUT","CHE","XXX"]},
+	{"cca3":"ISL"}
+]`
+
+func newAnalyticsGraph(t *testing.T) *Graph {
+	t.Helper()
+
+	var countries []restclient.Country
+	if err := json.Unmarshal([]byte(analyticsCountries), &countries); err != nil {
+		t.Fatalf("invalid test countries: %v", err)
+	}
+	return NewGraph(countries)
+}
+
+func TestGraphComponents(t *testing.T) {
+	t.Parallel()
+
+	want := [][]string{{"AUT", "CHE", "DEU", "LIE"}, {"LSO", "ZAF"}, {"ISL"}}
+	if got := newAnalyticsGraph(t).Components(); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected components %v, got %v", want, got)
+	}
+}
+
+func TestGraphEnclavesAndDoublyLandlocked(t *testing.T) {
+	t.Parallel()
+
+	g := newAnalyticsGraph(t)
+	if got, want := g.Enclaves(), []string{"LSO"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("expected enclaves %v, got %v", want, got)
+	}
+	if got, want := g.DoublyLandlocked(), []string{"LIE"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("expected doubly landlocked countries %v, got %v", want, got)
+	}
+}
+
+func TestGraphRanking(t *testing.T) {
+	t.Parallel()
+
+	want := []string{"AUT", "CHE", "DEU", "LIE", "LSO", "ZAF", "ISL"}
+	if got := newAnalyticsGraph(t).Ranking(); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected ranking %v, got %v", want, got)
+	}
+}
+
+func TestGraphAsymmetries(t *testing.T) {
+	t.Parallel()
+
+	want := []Asymmetry{{Country: "DEU", Border: "CHE"}}
+	if got := newAnalyticsGraph(t).Asymmetries(); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected asymmetries %v, got %v", want, got)
+	}
+}
@@ -0,0 +1,281 @@
+package borders
+
+import (
+	"context"
+	"countryinfo/internal/restclient"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Graph is the land border graph of all countries, keyed by upper-case
+// alpha-3 code. A border listed by either of two countries connects them.
+type Graph struct {
+	countries map[string]restclient.Country
+	adjacent  map[string][]string
+	// codes maps the alpha-2 and numeric code of each country to its
+	// alpha-3 code.
+	codes map[string]string
+	// missing lists border codes that name no known country, by the
+	// country listing them.
+	missing map[string][]string
+}
+
+// NewGraph builds the border graph of countries.
+func NewGraph(countries []restclient.Country) *Graph {
+	g := &Graph{
+		countries: make(map[string]restclient.Country, len(countries)),
+		adjacent:  make(map[string][]string, len(countries)),
+		codes:     make(map[string]string, 2*len(countries)),
+		missing:   make(map[string][]string),
+	}
+	for _, c := range countries {
+		code := strings.ToUpper(c.CCA3)
+		if code == "" {
+			continue
+		}
+		g.countries[code] = c
+		for _, other := range []string{c.CCA2, c.CCN3} {
+			if other != "" {
+				g.codes[strings.ToUpper(other)] = code
+			}
+		}
+	}
+	for code, c := range g.countries {
+		for _, border := range c.Borders {
+			border = strings.ToUpper(border)
+			if _, ok := g.countries[border]; !ok {
+				g.missing[code] = append(g.missing[code], border)
+				continue
+			}
+			g.connect(code, border)
+			g.connect(border, code)
+		}
+	}
+	for _, neighbours := range g.adjacent {
+		slices.Sort(neighbours)
+	}
+	return g
+}
+
+func (g *Graph) connect(from, to string) {
+	if !slices.Contains(g.adjacent[from], to) {
+		g.adjacent[from] = append(g.adjacent[from], to)
+	}
+}
+
+// Country returns the country with the given alpha-3 code.
+func (g *Graph) Country(code string) (restclient.Country, bool) {
+	c, ok := g.countries[strings.ToUpper(code)]
+	return c, ok
+}
+
+// Lookup returns the country with the given alpha-2, alpha-3 or numeric code.
+func (g *Graph) Lookup(code string) (restclient.Country, bool) {
+	code = strings.ToUpper(code)
+	if c, ok := g.countries[code]; ok {
+		return c, true
+	}
+	c, ok := g.countries[g.codes[code]]
+	return c, ok
+}
+
+// Neighbours returns the sorted alpha-3 codes of the countries bordering code.
+func (g *Graph) Neighbours(code string) []string {
+	return g.adjacent[strings.ToUpper(code)]
+}
+
+// Missing returns the border codes of code that name no known country.
+func (g *Graph) Missing(code string) []string {
+	return g.missing[strings.ToUpper(code)]
+}
+
+// Within walks the graph breadth-first from code and returns the countries
+// reachable within depth land crossings, grouped by distance: element i holds
+// the sorted codes of the countries exactly i+1 crossings away. Trailing empty
+// groups are dropped.
+func (g *Graph) Within(code string, depth int) [][]string {
+	code = strings.ToUpper(code)
+	seen := map[string]bool{code: true}
+	frontier := []string{code}
+
+	var hops [][]string
+	for len(hops) < depth && len(frontier) > 0 {
+		var next []string
+		for _, from := range frontier {
+			for _, to := range g.adjacent[from] {
+				if !seen[to] {
+					seen[to] = true
+					next = append(next, to)
+				}
+			}
+		}
+		if len(next) == 0 {
+			break
+		}
+		slices.Sort(next)
+		hops = append(hops, next)
+		frontier = next
+	}
+	return hops
+}
+
+// Loader builds the border graph from the upstream country list and keeps it
+// in memory until the countries client refreshes the list.
+type Loader struct {
+	countries *restclient.CountriesClient
+
+	mu     sync.Mutex
+	source *restclient.Country // first element of the list the graph was built from
+	graph  *Graph
+}
+
+// NewLoader creates a Loader backed by countries.
+func NewLoader(countries *restclient.CountriesClient) *Loader {
+	return &Loader{countries: countries}
+}
+
+// Graph returns the border graph of all countries.
+func (l *Loader) Graph(ctx context.Context) (*Graph, error) {
+	countries, err := l.countries.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(countries) == 0 {
+		return nil, fmt.Errorf("countries endpoint returned no countries")
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	// The client caches the list, so an unchanged list is the same slice.
+	if l.graph == nil || l.source != &countries[0] {
+		l.graph = NewGraph(countries)
+		l.source = &countries[0]
+	}
+	return l.graph, nil
+}
+
+// Path returns a shortest land route from one country to another as the
+// alpha-3 codes of every country on it, both ends included, or nil if the two
+// are not connected. Countries in avoid are never entered. Of several equally
+// short routes, the one first in alphabetical order of its stops is returned.
+func (g *Graph) Path(from, to string, avoid map[string]bool) []string {
+	from, to = strings.ToUpper(from), strings.ToUpper(to)
+	if _, ok := g.countries[from]; !ok {
+		return nil
+	}
+	if from == to {
+		return []string{from}
+	}
+
+	previous := map[string]string{from: ""}
+	frontier := []string{from}
+	for len(frontier) > 0 {
+		var next []string
+		for _, at := range frontier {
+			for _, n := range g.adjacent[at] {
+				if _, seen := previous[n]; seen || avoid[n] {
+					continue
+				}
+				previous[n] = at
+				if n == to {
+					return g.trace(previous, to)
+				}
+				next = append(next, n)
+			}
+		}
+		frontier = next
+	}
+	return nil
+}
+
+// trace follows previous back from the end of a path to its start.
+func (g *Graph) trace(previous map[string]string, end string) []string {
+	var path []string
+	for at := end; at != ""; at = previous[at] {
+		path = append(path, at)
+	}
+	slices.Reverse(path)
+	return path
+}
+
+// Edges returns every border once as a pair of alpha-3 codes, the lesser
+// first, ordered by the first code and then the second.
+func (g *Graph) Edges() [][2]string {
+	var edges [][2]string
+	for _, from := range slices.Sorted(maps.Keys(g.adjacent)) {
+		for _, to := range g.adjacent[from] {
+			if from < to {
+				edges = append(edges, [2]string{from, to})
+			}
+		}
+	}
+	return edges
+}
+
+// ShortestPaths returns every shortest land route from a country to any
+// country for which target reports true, each as the alpha-3 codes of the
+// countries on it, both ends included, in alphabetical order of their stops.
+// It returns nil if no such country is reachable, and a single one-stop route
+// if the country itself is a target.
+func (g *Graph) ShortestPaths(from string, target func(code string) bool) [][]string {
+	from = strings.ToUpper(from)
+	if _, ok := g.countries[from]; !ok {
+		return nil
+	}
+	if target(from) {
+		return [][]string{{from}}
+	}
+
+	// previous holds every neighbour one crossing closer to from, so that
+	// all equally short routes can be traced back.
+	distance := map[string]int{from: 0}
+	previous := make(map[string][]string)
+	frontier := []string{from}
+	for d := 1; len(frontier) > 0; d++ {
+		var next, reached []string
+		for _, at := range frontier {
+			for _, n := range g.adjacent[at] {
+				seen, ok := distance[n]
+				if !ok {
+					distance[n] = d
+					next = append(next, n)
+					if target(n) {
+						reached = append(reached, n)
+					}
+				} else if seen != d {
+					continue
+				}
+				previous[n] = append(previous[n], at)
+			}
+		}
+		if len(reached) > 0 {
+			var paths [][]string
+			for _, end := range reached {
+				paths = append(paths, g.traceAll(previous, end)...)
+			}
+			slices.SortFunc(paths, slices.Compare)
+			return paths
+		}
+		frontier = next
+	}
+	return nil
+}
+
+// traceAll follows every chain of previous back from the end of a path to
+// its start.
+func (g *Graph) traceAll(previous map[string][]string, end string) [][]string {
+	parents := previous[end]
+	if len(parents) == 0 {
+		return [][]string{{end}}
+	}
+	var paths [][]string
+	for _, parent := range parents {
+		for _, path := range g.traceAll(previous, parent) {
+			paths = append(paths, append(slices.Clone(path), end))
+		}
+	}
+	return paths
+}
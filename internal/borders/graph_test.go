@@ -0,0 +1,174 @@
+package borders
+
+import (
+	"context"
+	"countryinfo/internal/restclient"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"slices"
+	"sync/atomic"
+	"testing"
+)
+
+// testCountries is a small slice of the European border graph. Sweden omits
+// its border with Finland and Finland lists an unknown code, to exercise
+// asymmetric and dangling borders.
+const testCountries = `[
+	{"cca3":"NOR","borders":["FIN","SWE","RUS"]},
+	{"cca3":"SWE","borders":["NOR"]},
+	{"cca3":"FIN","borders":["NOR","SWE","RUS","XXX"]},
+	{"cca3":"RUS","borders":["NOR","FIN","EST"]},
+	{"cca3":"EST","borders":["RUS","LVA"]},
+	{"cca3":"LVA","borders":["EST"]},
+	{"cca3":"ISL"}
+]`
+
+func newTestGraph(t *testing.T) *Graph {
+	t.Helper()
+
+	var countries []restclient.Country
+	if err := json.Unmarshal([]byte(testCountries), &countries); err != nil {
+		t.Fatalf("invalid test countries: %v", err)
+	}
+	return NewGraph(countries)
+}
+
+func TestGraphNeighbours(t *testing.T) {
+	t.Parallel()
+
+	g := newTestGraph(t)
+	if got, want := g.Neighbours("swe"), []string{"FIN", "NOR"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("expected Sweden to border %v, got %v", want, got)
+	}
+	if got := g.Neighbours("ISL"); len(got) != 0 {
+		t.Errorf("expected Iceland to have no neighbours, got %v", got)
+	}
+	if got, want := g.Missing("FIN"), []string{"XXX"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("expected missing borders %v, got %v", want, got)
+	}
+}
+
+func TestGraphLookup(t *testing.T) {
+	t.Parallel()
+
+	g := NewGraph([]restclient.Country{{CCA2: "NO", CCA3: "NOR", CCN3: "578"}, {CCA3: "SWE"}})
+
+	for _, code := range []string{"NOR", "nor", "NO", "no", "578"} {
+		if c, ok := g.Lookup(code); !ok || c.CCA3 != "NOR" {
+			t.Errorf("Lookup(%q) = %q, %v, expected NOR", code, c.CCA3, ok)
+		}
+	}
+	for _, code := range []string{"SE", "752", "XYZ", ""} {
+		if c, ok := g.Lookup(code); ok {
+			t.Errorf("Lookup(%q) = %q, expected no country", code, c.CCA3)
+		}
+	}
+}
+
+func TestGraphWithin(t *testing.T) {
+	t.Parallel()
+
+	g := newTestGraph(t)
+	tests := []struct {
+		code  string
+		depth int
+		want  [][]string
+	}{
+		{"NOR", 1, [][]string{{"FIN", "RUS", "SWE"}}},
+		{"NOR", 2, [][]string{{"FIN", "RUS", "SWE"}, {"EST"}}},
+		{"NOR", 10, [][]string{{"FIN", "RUS", "SWE"}, {"EST"}, {"LVA"}}},
+		{"LVA", 2, [][]string{{"EST"}, {"RUS"}}},
+		{"ISL", 3, nil},
+	}
+	for _, tt := range tests {
+		if got := g.Within(tt.code, tt.depth); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Within(%s, %d) = %v, want %v", tt.code, tt.depth, got, tt.want)
+		}
+	}
+}
+
+func TestGraphEdges(t *testing.T) {
+	t.Parallel()
+
+	want := [][2]string{{"EST", "LVA"}, {"EST", "RUS"}, {"FIN", "NOR"}, {"FIN", "RUS"}, {"FIN", "SWE"}, {"NOR", "RUS"}, {"NOR", "SWE"}}
+	if got := newTestGraph(t).Edges(); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected edges %v, got %v", want, got)
+	}
+}
+
+func TestGraphPath(t *testing.T) {
+	t.Parallel()
+
+	g := newTestGraph(t)
+	tests := []struct {
+		from, to string
+		avoid    map[string]bool
+		want     []string
+	}{
+		{"swe", "lva", nil, []string{"SWE", "FIN", "RUS", "EST", "LVA"}},
+		{"NOR", "NOR", nil, []string{"NOR"}},
+		{"NOR", "EST", map[string]bool{"RUS": true}, nil},
+		{"SWE", "RUS", map[string]bool{"FIN": true}, []string{"SWE", "NOR", "RUS"}},
+		{"NOR", "ISL", nil, nil},
+		{"XXX", "NOR", nil, nil},
+	}
+	for _, tt := range tests {
+		if got := g.Path(tt.from, tt.to, tt.avoid); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Path(%s, %s, %v) = %v, want %v", tt.from, tt.to, tt.avoid, got, tt.want)
+		}
+	}
+}
+
+func TestGraphShortestPaths(t *testing.T) {
+	t.Parallel()
+
+	g := newTestGraph(t)
+	isTarget := func(codes ...string) func(string) bool {
+		return func(code string) bool { return slices.Contains(codes, code) }
+	}
+	tests := []struct {
+		from    string
+		targets []string
+		want    [][]string
+	}{
+		{"LVA", []string{"NOR", "SWE"}, [][]string{{"LVA", "EST", "RUS", "NOR"}}},
+		{"EST", []string{"SWE"}, [][]string{{"EST", "RUS", "FIN", "SWE"}, {"EST", "RUS", "NOR", "SWE"}}},
+		{"swe", []string{"SWE"}, [][]string{{"SWE"}}},
+		{"ISL", []string{"NOR"}, nil},
+	}
+	for _, tt := range tests {
+		if got := g.ShortestPaths(tt.from, isTarget(tt.targets...)); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ShortestPaths(%s, %v) = %v, want %v", tt.from, tt.targets, got, tt.want)
+		}
+	}
+}
+
+func TestLoaderReusesGraphWhileListIsCached(t *testing.T) {
+	t.Parallel()
+
+	var hits atomic.Int32
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(testCountries))
+	}))
+	defer upstream.Close()
+
+	loader := NewLoader(restclient.NewCountriesClient(upstream.URL))
+	first, err := loader.Graph(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	second, err := loader.Graph(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if first != second {
+		t.Error("expected the cached graph to be reused")
+	}
+	if got := hits.Load(); got != 1 {
+		t.Errorf("expected 1 upstream request, got %d", got)
+	}
+}
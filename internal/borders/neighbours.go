@@ -0,0 +1,85 @@
+// Package borders resolves the countries bordering a country and analyses
+// the land border graph of all countries.
+package borders
+
+import (
+	"context"
+	"countryinfo/internal/fanout"
+	"countryinfo/internal/restclient"
+	"errors"
+	"log/slog"
+	"strings"
+)
+
+// Neighbour is a resolved bordering country and the border code it was found by.
+type Neighbour struct {
+	Code    string
+	Country restclient.Country
+}
+
+// Unresolved is a border code that could not be resolved and why.
+type Unresolved struct {
+	Code   string
+	Reason string
+}
+
+// Resolve looks up the bordering countries with one batch request, falling
+// back to concurrent single lookups, at most concurrency at a time, if the
+// batch request fails. Neighbours are returned in the order of codes, and
+// every code that could not be resolved is reported as unresolved.
+func Resolve(ctx context.Context, countries *restclient.CountriesClient, codes []string, concurrency int) ([]Neighbour, []Unresolved) {
+	var neighbours []Neighbour
+	var unresolved []Unresolved
+
+	batch, err := countries.GetByCodes(ctx, codes)
+	if err == nil {
+		for _, code := range codes {
+			code = strings.ToUpper(code)
+			if country, ok := batch.Countries[code]; ok {
+				neighbours = append(neighbours, Neighbour{Code: code, Country: country})
+			}
+		}
+		for _, code := range batch.Unresolved {
+			slog.WarnContext(ctx, "failed to resolve border country", "border_code", code)
+			unresolved = append(unresolved, Unresolved{Code: code, Reason: "country not found"})
+		}
+		return neighbours, unresolved
+	}
+	if ctx.Err() == nil {
+		slog.WarnContext(ctx, "batch border lookup failed, resolving borders individually", "error", err)
+	}
+
+	lookups := fanout.Map(ctx, codes, concurrency, func(ctx context.Context, code string) (restclient.Country, error) {
+		found, err := countries.GetByAlpha(ctx, code)
+		if err != nil {
+			return restclient.Country{}, err
+		}
+		if len(found) == 0 {
+			return restclient.Country{}, restclient.ErrNotFound
+		}
+		return found[0], nil
+	})
+
+	for i, lookup := range lookups {
+		code := strings.ToUpper(codes[i])
+		if lookup.Err != nil {
+			slog.WarnContext(ctx, "failed to look up border country", "error", lookup.Err, "border_code", code)
+			unresolved = append(unresolved, Unresolved{Code: code, Reason: FailureReason(lookup.Err)})
+			continue
+		}
+		neighbours = append(neighbours, Neighbour{Code: code, Country: lookup.Value})
+	}
+	return neighbours, unresolved
+}
+
+// FailureReason describes a failed upstream lookup for a client.
+func FailureReason(err error) string {
+	switch {
+	case errors.Is(err, restclient.ErrNotFound):
+		return "country not found"
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return "lookup cancelled"
+	default:
+		return restclient.ErrorMessage(err)
+	}
+}
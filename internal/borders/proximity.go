@@ -0,0 +1,71 @@
+package borders
+
+import (
+	"cmp"
+	"countryinfo/internal/restclient"
+	"math"
+	"slices"
+	"strings"
+)
+
+// earthRadiusKm is the mean radius of the Earth.
+const earthRadiusKm = 6371.0
+
+// Position returns the coordinates a country's distance to others is measured
+// from: its capital if known, otherwise the centre of the country.
+func Position(c restclient.Country) (lat, lng float64, ok bool) {
+	if latlng := c.CapitalInfo.LatLng; len(latlng) >= 2 {
+		return latlng[0], latlng[1], true
+	}
+	if len(c.LatLng) >= 2 {
+		return c.LatLng[0], c.LatLng[1], true
+	}
+	return 0, 0, false
+}
+
+// GreatCircleKm returns the great-circle distance in kilometres between two
+// points given in degrees, using the haversine formula.
+func GreatCircleKm(lat1, lng1, lat2, lng2 float64) float64 {
+	toRad := func(deg float64) float64 { return deg * math.Pi / 180 }
+	dLat := toRad(lat2 - lat1)
+	dLng := toRad(lng2 - lng1)
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(toRad(lat1))*math.Cos(toRad(lat2))*math.Sin(dLng/2)*math.Sin(dLng/2)
+	return 2 * earthRadiusKm * math.Asin(math.Min(1, math.Sqrt(h)))
+}
+
+// NearbyCountry is a country within some distance of another.
+type NearbyCountry struct {
+	Code       string
+	Country    restclient.Country
+	DistanceKm float64
+}
+
+// Nearby returns the countries within radiusKm of code, measured between the
+// positions returned by Position, nearest first. Countries without
+// coordinates are never nearby.
+func (g *Graph) Nearby(code string, radiusKm float64) []NearbyCountry {
+	code = strings.ToUpper(code)
+	lat, lng, ok := Position(g.countries[code])
+	if !ok {
+		return nil
+	}
+
+	var nearby []NearbyCountry
+	for other, c := range g.countries {
+		if other == code {
+			continue
+		}
+		otherLat, otherLng, ok := Position(c)
+		if !ok {
+			continue
+		}
+		if d := GreatCircleKm(lat, lng, otherLat, otherLng); d <= radiusKm {
+			nearby = append(nearby, NearbyCountry{Code: other, Country: c, DistanceKm: d})
+		}
+	}
+	slices.SortFunc(nearby, func(a, b NearbyCountry) int {
+		return cmp.Or(cmp.Compare(a.DistanceKm, b.DistanceKm), cmp.Compare(a.Code, b.Code))
+	})
+	return nearby
+}
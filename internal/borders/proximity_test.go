@@ -0,0 +1,59 @@
+package borders
+
+import (
+	"countryinfo/internal/restclient"
+	"encoding/json"
+	"math"
+	"testing"
+)
+
+func TestGreatCircleKm(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name                   string
+		lat1, lng1, lat2, lng2 float64
+		want                   float64
+	}{
+		{"same point", 64.15, -21.95, 64.15, -21.95, 0},
+		{"Reykjavik to Oslo", 64.15, -21.95, 59.92, 10.75, 1746},
+		{"quarter meridian", 0, 0, 90, 0, 10008},
+	}
+	for _, tt := range tests {
+		if got := GreatCircleKm(tt.lat1, tt.lng1, tt.lat2, tt.lng2); math.Abs(got-tt.want) > 1 {
+			t.Errorf("%s: expected %.0f km, got %.1f km", tt.name, tt.want, got)
+		}
+	}
+}
+
+func TestGraphNearby(t *testing.T) {
+	t.Parallel()
+
+	var countries []restclient.Country
+	err := json.Unmarshal([]byte(`[
+		{"cca3":"ISL","latlng":[65,-18],"capitalInfo":{"latlng":[64.15,-21.95]}},
+		{"cca3":"FRO","latlng":[62,-7]},
+		{"cca3":"NOR","latlng":[62,10],"capitalInfo":{"latlng":[59.92,10.75]}},
+		{"cca3":"GRL","latlng":[72,-40],"capitalInfo":{"latlng":[64.18,-51.75]}},
+		{"cca3":"ATA"}
+	]`), &countries)
+	if err != nil {
+		t.Fatalf("invalid test countries: %v", err)
+	}
+	g := NewGraph(countries)
+
+	nearby := g.Nearby("isl", 1500)
+	var codes []string
+	for _, n := range nearby {
+		codes = append(codes, n.Code)
+	}
+	if len(codes) != 2 || codes[0] != "FRO" || codes[1] != "GRL" {
+		t.Fatalf("expected FRO and GRL nearest first, got %v", codes)
+	}
+	if got := nearby[1].DistanceKm; math.Abs(got-1431) > 1 {
+		t.Errorf("expected Nuuk about 1431 km from Reykjavik, got %.1f", got)
+	}
+	if got := g.Nearby("ATA", 20000); got != nil {
+		t.Errorf("expected no nearby countries without coordinates, got %v", got)
+	}
+}
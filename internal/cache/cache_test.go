@@ -0,0 +1,182 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	c := New[string, int](2, time.Minute)
+	c.Set("a", 1)
+	c.Set("b", 2)
+
+	// Touch "a" so "b" becomes the least recently used entry.
+	if _, ok := c.Get("a"); !ok {
+		t.Fatal("expected a to be cached")
+	}
+	c.Set("c", 3)
+
+	if _, ok := c.Get("b"); ok {
+		t.Fatal("expected b to be evicted")
+	}
+	if v, ok := c.Get("a"); !ok || v != 1 {
+		t.Fatalf("expected a=1, got %d (ok=%v)", v, ok)
+	}
+	if v, ok := c.Get("c"); !ok || v != 3 {
+		t.Fatalf("expected c=3, got %d (ok=%v)", v, ok)
+	}
+
+	stats := c.Stats()
+	if stats.Evictions != 1 {
+		t.Errorf("expected 1 eviction, got %d", stats.Evictions)
+	}
+	if stats.Hits != 3 || stats.Misses != 1 {
+		t.Errorf("expected 3 hits and 1 miss, got %d hits and %d misses", stats.Hits, stats.Misses)
+	}
+	if stats.Size != 2 {
+		t.Errorf("expected size 2, got %d", stats.Size)
+	}
+}
+
+func TestCacheExpiresEntries(t *testing.T) {
+	now := time.Now()
+	c := New[string, int](10, time.Minute)
+	c.now = func() time.Time { return now }
+
+	c.Set("long", 1)
+	c.SetWithTTL("short", 2, time.Second)
+
+	now = now.Add(2 * time.Second)
+	if _, ok := c.Get("short"); ok {
+		t.Fatal("expected short-lived entry to expire")
+	}
+	if _, ok := c.Get("long"); !ok {
+		t.Fatal("expected long-lived entry to remain")
+	}
+
+	if got := c.Stats().Expirations; got != 1 {
+		t.Errorf("expected 1 expiration, got %d", got)
+	}
+}
+
+func TestCacheWithZeroCapacityStoresNothing(t *testing.T) {
+	c := New[string, int](0, time.Minute)
+	c.Set("a", 1)
+
+	if _, ok := c.Get("a"); ok {
+		t.Fatal("expected disabled cache to miss")
+	}
+}
+
+func TestGroupCoalescesConcurrentCalls(t *testing.T) {
+	var g Group[string, int]
+	var calls atomic.Int32
+	release := make(chan struct{})
+
+	const callers = 10
+	var wg sync.WaitGroup
+	wg.Add(callers)
+	results := make([]int, callers)
+	for i := range callers {
+		go func() {
+			defer wg.Done()
+			v, err := g.Do(context.Background(), "key", func(context.Context) (int, error) {
+				calls.Add(1)
+				<-release
+				return 42, nil
+			})
+			if err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			results[i] = v
+		}()
+	}
+
+	// Release fn only once every caller is provably waiting for it, so none
+	// can arrive late and start a call of its own.
+	for g.Waiters("key") < callers {
+		runtime.Gosched()
+	}
+	close(release)
+	wg.Wait()
+
+	if got := calls.Load(); got != 1 {
+		t.Fatalf("expected fn to run once, ran %d times", got)
+	}
+	for i, v := range results {
+		if v != 42 {
+			t.Errorf("caller %d got %d, want 42", i, v)
+		}
+	}
+}
+
+func TestGroupHonoursCallerContext(t *testing.T) {
+	var g Group[string, int]
+	release := make(chan struct{})
+	defer close(release)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := g.Do(ctx, "key", func(context.Context) (int, error) {
+		<-release
+		return 1, nil
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+}
+
+func TestGroupCancelsCallWhenEveryCallerLeaves(t *testing.T) {
+	var g Group[string, int]
+	started := make(chan struct{})
+	cancelled := make(chan struct{})
+
+	first, cancelFirst := context.WithCancel(context.Background())
+	second, cancelSecond := context.WithCancel(context.Background())
+	errs := make(chan error, 2)
+	for _, ctx := range []context.Context{first, second} {
+		go func() {
+			_, err := g.Do(ctx, "key", func(ctx context.Context) (int, error) {
+				close(started)
+				<-ctx.Done()
+				close(cancelled)
+				return 0, ctx.Err()
+			})
+			errs <- err
+		}()
+	}
+	<-started
+	for g.Waiters("key") < 2 {
+		runtime.Gosched()
+	}
+
+	// The call keeps running while a caller still waits for it.
+	cancelFirst()
+	if err := <-errs; !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	select {
+	case <-cancelled:
+		t.Fatal("expected the call to survive while a caller waits")
+	default:
+	}
+
+	cancelSecond()
+	if err := <-errs; !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	select {
+	case <-cancelled:
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected the call to be cancelled once every caller left")
+	}
+	if got := g.Waiters("key"); got != 0 {
+		t.Errorf("expected no waiters left, got %d", got)
+	}
+}
@@ -1,8 +1,14 @@
 package config
 
 import (
+	"countryinfo/internal/fanout"
+	"countryinfo/internal/restclient"
+	"errors"
 	"fmt"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 )
 
 type EnvVar string
@@ -18,12 +24,61 @@ func (e EnvVar) GetOrDefault(defaultValue string) string {
 	return defaultValue
 }
 
+// IntOrDefault parses the variable as an integer, returning defaultValue when unset.
+func (e EnvVar) IntOrDefault(defaultValue int) (int, error) {
+	raw := e.Get()
+	if raw == "" {
+		return defaultValue, nil
+	}
+	v, err := strconv.Atoi(raw)
+	if err != nil {
+		return defaultValue, fmt.Errorf("%s must be an integer: %w", e, err)
+	}
+	return v, nil
+}
+
+// DurationOrDefault parses the variable as a time.Duration (e.g. "90s", "12h"),
+// returning defaultValue when unset.
+func (e EnvVar) DurationOrDefault(defaultValue time.Duration) (time.Duration, error) {
+	raw := e.Get()
+	if raw == "" {
+		return defaultValue, nil
+	}
+	v, err := time.ParseDuration(raw)
+	if err != nil {
+		return defaultValue, fmt.Errorf("%s must be a duration: %w", e, err)
+	}
+	return v, nil
+}
+
 const (
-	Port              EnvVar = "PORT"
-	CountriesEndpoint EnvVar = "COUNTRIES_ENDPOINT"
-	CurrencyEndpoint  EnvVar = "CURRENCY_ENDPOINT"
+	Port                 EnvVar = "PORT"
+	CountriesEndpoint    EnvVar = "COUNTRIES_ENDPOINT"
+	CurrencyEndpoint     EnvVar = "CURRENCY_ENDPOINT"
+	CountriesCacheSize   EnvVar = "COUNTRIES_CACHE_SIZE"
+	CountriesCacheTTL    EnvVar = "COUNTRIES_CACHE_TTL"
+	CountriesNotFoundTTL EnvVar = "COUNTRIES_NOT_FOUND_TTL"
+	RatesCacheTTL        EnvVar = "RATES_CACHE_TTL"
+	RatesStaleTTL        EnvVar = "RATES_STALE_TTL"
+
+	CountriesRetryMaxAttempts EnvVar = "COUNTRIES_RETRY_MAX_ATTEMPTS"
+	CountriesRetryBaseDelay   EnvVar = "COUNTRIES_RETRY_BASE_DELAY"
+	CountriesRetryMaxDelay    EnvVar = "COUNTRIES_RETRY_MAX_DELAY"
+	CurrencyRetryMaxAttempts  EnvVar = "CURRENCY_RETRY_MAX_ATTEMPTS"
+	CurrencyRetryBaseDelay    EnvVar = "CURRENCY_RETRY_BASE_DELAY"
+	CurrencyRetryMaxDelay     EnvVar = "CURRENCY_RETRY_MAX_DELAY"
+
+	BreakerFailureThreshold EnvVar = "BREAKER_FAILURE_THRESHOLD"
+	BreakerOpenTimeout      EnvVar = "BREAKER_OPEN_TIMEOUT"
+
+	NeighbourConcurrency EnvVar = "NEIGHBOUR_CONCURRENCY"
+	ConvertPivots        EnvVar = "CONVERT_PIVOT_CURRENCIES"
 )
 
+// defaultConvertPivots is the only default kept here; other unset settings
+// fall back to the defaults exported by the packages they configure.
+const defaultConvertPivots = "USD,EUR"
+
 var (
 	CountryAPIEndpointRequired  = envRequiredErr(CountriesEndpoint)
 	CurrencyAPIEndpointRequired = envRequiredErr(CurrencyEndpoint)
@@ -32,10 +87,15 @@ var (
 type Config struct {
 	ServerSetting
 	APIEndpoint
+	CacheSetting
+	RetrySetting
+	BreakerSetting
 }
 
 type ServerSetting struct {
-	Port string
+	Port                 string
+	NeighbourConcurrency int
+	ConvertPivots        []string
 }
 
 type APIEndpoint struct {
@@ -43,17 +103,115 @@ type APIEndpoint struct {
 	CurrencyEndpoint  string
 }
 
+type CacheSetting struct {
+	CountriesCacheSize   int
+	CountriesCacheTTL    time.Duration
+	CountriesNotFoundTTL time.Duration
+	RatesCacheTTL        time.Duration
+	RatesStaleTTL        time.Duration
+}
+
+// Retry describes the retry policy for one upstream.
+type Retry struct {
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+}
+
+type RetrySetting struct {
+	CountriesRetry Retry
+	CurrencyRetry  Retry
+}
+
+// BreakerSetting is shared by the circuit breakers of all upstreams.
+type BreakerSetting struct {
+	BreakerFailureThreshold int
+	BreakerOpenTimeout      time.Duration
+}
+
 func Load() (*Config, error) {
+	concurrency, err := NeighbourConcurrency.IntOrDefault(fanout.DefaultLimit)
+	if err != nil {
+		return nil, err
+	}
+	cacheSetting, err := loadCacheSetting()
+	if err != nil {
+		return nil, err
+	}
+	retrySetting, err := loadRetrySetting()
+	if err != nil {
+		return nil, err
+	}
+	breakerSetting, err := loadBreakerSetting()
+	if err != nil {
+		return nil, err
+	}
+
 	cfg := &Config{
-		ServerSetting{Port.GetOrDefault("8080")},
+		ServerSetting{
+			Port:                 Port.GetOrDefault("8080"),
+			NeighbourConcurrency: concurrency,
+			ConvertPivots:        strings.Split(ConvertPivots.GetOrDefault(defaultConvertPivots), ","),
+		},
 		APIEndpoint{
 			CountriesEndpoint: CountriesEndpoint.Get(),
 			CurrencyEndpoint:  CurrencyEndpoint.Get(),
 		},
+		cacheSetting,
+		retrySetting,
+		breakerSetting,
 	}
 	return cfg, validateConfig(cfg)
 }
 
+func loadCacheSetting() (CacheSetting, error) {
+	size, sizeErr := CountriesCacheSize.IntOrDefault(restclient.DefaultCountriesCacheSize)
+	ttl, ttlErr := CountriesCacheTTL.DurationOrDefault(restclient.DefaultCountriesCacheTTL)
+	notFoundTTL, notFoundErr := CountriesNotFoundTTL.DurationOrDefault(restclient.DefaultCountriesNotFoundTTL)
+	ratesTTL, ratesErr := RatesCacheTTL.DurationOrDefault(restclient.DefaultRatesFreshFor)
+	staleTTL, staleErr := RatesStaleTTL.DurationOrDefault(restclient.DefaultRatesStaleFor)
+
+	return CacheSetting{
+		CountriesCacheSize:   size,
+		CountriesCacheTTL:    ttl,
+		CountriesNotFoundTTL: notFoundTTL,
+		RatesCacheTTL:        ratesTTL,
+		RatesStaleTTL:        staleTTL,
+	}, errors.Join(sizeErr, ttlErr, notFoundErr, ratesErr, staleErr)
+}
+
+func loadRetrySetting() (RetrySetting, error) {
+	countries, countriesErr := loadRetry(CountriesRetryMaxAttempts, CountriesRetryBaseDelay, CountriesRetryMaxDelay)
+	currency, currencyErr := loadRetry(CurrencyRetryMaxAttempts, CurrencyRetryBaseDelay, CurrencyRetryMaxDelay)
+
+	return RetrySetting{
+		CountriesRetry: countries,
+		CurrencyRetry:  currency,
+	}, errors.Join(countriesErr, currencyErr)
+}
+
+func loadRetry(maxAttempts, baseDelay, maxDelay EnvVar) (Retry, error) {
+	attempts, attemptsErr := maxAttempts.IntOrDefault(restclient.DefaultRetryPolicy.MaxAttempts)
+	base, baseErr := baseDelay.DurationOrDefault(restclient.DefaultRetryPolicy.BaseDelay)
+	ceiling, ceilingErr := maxDelay.DurationOrDefault(restclient.DefaultRetryPolicy.MaxDelay)
+
+	return Retry{
+		MaxAttempts: attempts,
+		BaseDelay:   base,
+		MaxDelay:    ceiling,
+	}, errors.Join(attemptsErr, baseErr, ceilingErr)
+}
+
+func loadBreakerSetting() (BreakerSetting, error) {
+	threshold, thresholdErr := BreakerFailureThreshold.IntOrDefault(restclient.DefaultBreakerSettings.FailureThreshold)
+	timeout, timeoutErr := BreakerOpenTimeout.DurationOrDefault(restclient.DefaultBreakerSettings.OpenTimeout)
+
+	return BreakerSetting{
+		BreakerFailureThreshold: threshold,
+		BreakerOpenTimeout:      timeout,
+	}, errors.Join(thresholdErr, timeoutErr)
+}
+
 func validateConfig(cfg *Config) error {
 	if cfg.CountriesEndpoint == "" {
 		return CountryAPIEndpointRequired
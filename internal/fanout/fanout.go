@@ -0,0 +1,47 @@
+// Package fanout runs independent calls concurrently with bounded parallelism.
+package fanout
+
+import (
+	"context"
+	"sync"
+)
+
+// DefaultLimit is the number of calls Map callers keep in flight unless
+// configured otherwise.
+const DefaultLimit = 8
+
+// Result pairs the value returned by one call with its error.
+type Result[R any] struct {
+	Value R
+	Err   error
+}
+
+// Map calls fn for every item with at most limit calls in flight and returns
+// the results in the order of items, whatever order the calls finish in. A
+// limit below 1 runs the calls one at a time. Once ctx is cancelled no further
+// calls are started and the remaining items report ctx.Err().
+func Map[T, R any](ctx context.Context, items []T, limit int, fn func(context.Context, T) (R, error)) []Result[R] {
+	results := make([]Result[R], len(items))
+	sem := make(chan struct{}, max(limit, 1))
+
+	var wg sync.WaitGroup
+	for i, item := range items {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if err := ctx.Err(); err != nil {
+			for j := i; j < len(items); j++ {
+				results[j].Err = err
+			}
+			break
+		}
+
+		wg.Go(func() {
+			defer func() { <-sem }()
+			results[i].Value, results[i].Err = fn(ctx, item)
+		})
+	}
+	wg.Wait()
+	return results
+}
@@ -0,0 +1,68 @@
+package fanout
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestMapPreservesOrder(t *testing.T) {
+	items := []int{5, 1, 4, 2, 3}
+	results := Map(context.Background(), items, 3, func(_ context.Context, n int) (int, error) {
+		// Later items finish first.
+		time.Sleep(time.Duration(n) * time.Millisecond)
+		return n * 10, nil
+	})
+
+	for i, r := range results {
+		if r.Err != nil {
+			t.Fatalf("unexpected error: %v", r.Err)
+		}
+		if r.Value != items[i]*10 {
+			t.Errorf("result %d = %d, want %d", i, r.Value, items[i]*10)
+		}
+	}
+}
+
+func TestMapBoundsParallelism(t *testing.T) {
+	const limit = 3
+	var inFlight, peak atomic.Int32
+
+	Map(context.Background(), make([]struct{}, 20), limit, func(context.Context, struct{}) (struct{}, error) {
+		n := inFlight.Add(1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(2 * time.Millisecond)
+		inFlight.Add(-1)
+		return struct{}{}, nil
+	})
+
+	if got := peak.Load(); got > limit {
+		t.Fatalf("expected at most %d calls in flight, saw %d", limit, got)
+	}
+}
+
+func TestMapStopsOnCancellation(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	var calls atomic.Int32
+
+	results := Map(ctx, make([]int, 10), 1, func(context.Context, int) (int, error) {
+		if calls.Add(1) == 2 {
+			cancel()
+		}
+		return 0, nil
+	})
+
+	if got := calls.Load(); got != 2 {
+		t.Fatalf("expected 2 calls before cancellation, got %d", got)
+	}
+	if !errors.Is(results[len(results)-1].Err, context.Canceled) {
+		t.Fatalf("expected remaining items to report context.Canceled, got %v", results[len(results)-1].Err)
+	}
+}
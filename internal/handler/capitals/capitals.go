@@ -0,0 +1,73 @@
+package capitals
+
+import (
+	"cmp"
+	"countryinfo/internal/handler/info"
+	"countryinfo/internal/restclient"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"slices"
+)
+
+// Response lists every capital city, sorted by name.
+type Response struct {
+	Count    int       `json:"count"`
+	Capitals []Capital `json:"capitals"`
+}
+
+// Capital is a capital city and the country it is the capital of.
+type Capital struct {
+	info.Capital
+	Country string `json:"country"`
+	Alpha2  string `json:"alpha2"`
+	Alpha3  string `json:"alpha3"`
+}
+
+type service struct {
+	countries *restclient.CountriesClient
+}
+
+// Handler serves the capital cities of all countries.
+func Handler(countries *restclient.CountriesClient) http.HandlerFunc {
+	s := &service{
+		countries: countries,
+	}
+	return s.capitalsHandler
+}
+
+func (s *service) capitalsHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	countries, err := s.countries.GetAll(ctx)
+	if err != nil {
+		slog.ErrorContext(ctx, "upstream countries request failed", "error", err)
+		http.Error(w, restclient.ErrorMessage(err), restclient.HTTPStatus(err))
+		return
+	}
+
+	capitals := make([]Capital, 0, len(countries))
+	for _, c := range countries {
+		for _, capital := range info.NewCapitals(c) {
+			capitals = append(capitals, Capital{
+				Capital: capital,
+				Country: c.Name.Common,
+				Alpha2:  c.CCA2,
+				Alpha3:  c.CCA3,
+			})
+		}
+	}
+	slices.SortFunc(capitals, func(a, b Capital) int {
+		return cmp.Or(cmp.Compare(a.Name, b.Name), cmp.Compare(a.Country, b.Country))
+	})
+
+	data, err := json.Marshal(Response{Count: len(capitals), Capitals: capitals})
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to marshal json", "error", err)
+		http.Error(w, "failed to marshal json", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(data)
+
+	slog.InfoContext(ctx, "capitals request completed", "capitals", len(capitals))
+}
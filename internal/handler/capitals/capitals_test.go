@@ -0,0 +1,43 @@
+package capitals
+
+import (
+	"countryinfo/internal/restclient"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCapitalsHandlerListsEveryCapital(t *testing.T) {
+	t.Parallel()
+
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v3.1/all" {
+			t.Errorf("unexpected upstream path %q", r.URL.Path)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`[
+			{"cca2":"NO","cca3":"NOR","name":{"common":"Norway"},"capital":["Oslo"],"capitalInfo":{"latlng":[59.92,10.75]}},
+			{"cca2":"ZA","cca3":"ZAF","name":{"common":"South Africa"},"capital":["Pretoria","Bloemfontein","Cape Town"],"capitalInfo":{"latlng":[-25.7,28.22]}},
+			{"cca2":"AQ","cca3":"ATA","name":{"common":"Antarctica"}}
+		]`))
+	}))
+	defer upstream.Close()
+
+	handler := Handler(restclient.NewCountriesClient(upstream.URL + "/v3.1"))
+	req := httptest.NewRequest(http.MethodGet, "/countryinfo/v1/capitals", nil)
+	w := httptest.NewRecorder()
+
+	handler(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", w.Code)
+	}
+	expected := `{"count":4,"capitals":[` +
+		`{"name":"Bloemfontein","country":"South Africa","alpha2":"ZA","alpha3":"ZAF"},` +
+		`{"name":"Cape Town","country":"South Africa","alpha2":"ZA","alpha3":"ZAF"},` +
+		`{"name":"Oslo","latlng":[59.92,10.75],"country":"Norway","alpha2":"NO","alpha3":"NOR"},` +
+		`{"name":"Pretoria","latlng":[-25.7,28.22],"country":"South Africa","alpha2":"ZA","alpha3":"ZAF"}]}`
+	if got := w.Body.String(); got != expected {
+		t.Fatalf("unexpected response body:\ngot:  %s\nwant: %s", got, expected)
+	}
+}
@@ -0,0 +1,110 @@
+package convert
+
+import (
+	"countryinfo/internal/restclient"
+	"countryinfo/internal/util"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Response is the result of converting an amount between two currencies.
+type Response struct {
+	From          string    `json:"from"`
+	To            string    `json:"to"`
+	Amount        float64   `json:"amount"`
+	Rate          float64   `json:"rate"`
+	Converted     float64   `json:"converted"`
+	Method        string    `json:"method"`
+	Path          []string  `json:"path"`
+	DataTimestamp time.Time `json:"data-timestamp"`
+	Stale         bool      `json:"stale,omitempty"`
+}
+
+type service struct {
+	currencies *restclient.CurrencyClient
+	pivots     []string
+}
+
+// Handler serves currency conversions, deriving cross rates through pivots
+// (e.g. USD, EUR) when the upstream has no direct or inverse rate.
+func Handler(currencies *restclient.CurrencyClient, pivots []string) http.HandlerFunc {
+	normalized := make([]string, 0, len(pivots))
+	for _, pivot := range pivots {
+		if pivot = strings.ToUpper(strings.TrimSpace(pivot)); util.IsCurrencyCode(pivot) {
+			normalized = append(normalized, pivot)
+		}
+	}
+	s := &service{
+		currencies: currencies,
+		pivots:     normalized,
+	}
+	return s.convertHandler
+}
+
+func (s *service) convertHandler(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	from := strings.ToUpper(strings.TrimSpace(query.Get("from")))
+	to := strings.ToUpper(strings.TrimSpace(query.Get("to")))
+	if !util.IsCurrencyCode(from) || !util.IsCurrencyCode(to) {
+		badRequest(w, fmt.Sprintf("from and to must be ISO 4217 currency codes, got from=%q to=%q", from, to))
+		return
+	}
+
+	amount := 1.0
+	if raw := strings.TrimSpace(query.Get("amount")); raw != "" {
+		parsed, err := util.ParseAmount(raw)
+		if err != nil {
+			badRequest(w, err.Error())
+			return
+		}
+		amount = parsed
+	}
+
+	ctx := r.Context()
+	d, err := s.deriveRate(ctx, from, to)
+	if errors.Is(err, errNoRate) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to derive exchange rate", "error", err, "from", from, "to", to)
+		http.Error(w, restclient.ErrorMessage(err), restclient.HTTPStatus(err))
+		return
+	}
+
+	resp := Response{
+		From:          from,
+		To:            to,
+		Amount:        amount,
+		Rate:          d.rate,
+		Converted:     amount * d.rate,
+		Method:        d.method,
+		Path:          d.path,
+		DataTimestamp: d.timestamp(),
+		Stale:         d.table != nil && d.table.Stale,
+	}
+
+	data, err := json.Marshal(resp)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to marshal json", "error", err)
+		http.Error(w, "failed to marshal json", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(data)
+
+	slog.InfoContext(ctx, "convert request completed",
+		"from", from,
+		"to", to,
+		"method", d.method,
+	)
+}
+
+func badRequest(w http.ResponseWriter, msg string) {
+	http.Error(w, fmt.Sprintf("%s\n%s", http.StatusText(http.StatusBadRequest), msg), http.StatusBadRequest)
+}
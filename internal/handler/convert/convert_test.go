@@ -0,0 +1,86 @@
+package convert
+
+import (
+	"countryinfo/internal/restclient"
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestConvertHandlerDerivesRates(t *testing.T) {
+	t.Parallel()
+
+	tables := map[string]string{
+		"/NOK": `{"base_code":"NOK","rates":{"NOK":1,"EUR":0.08}}`,
+		"/JPY": `{"base_code":"JPY","rates":{"JPY":1,"NOK":0.07}}`,
+		"/USD": `{"base_code":"USD","rates":{"USD":1,"ZAR":18,"JPY":150}}`,
+	}
+	currencyAPI := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, ok := tables[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(body))
+	}))
+	defer currencyAPI.Close()
+
+	handler := Handler(restclient.NewCurrencyClient(currencyAPI.URL), []string{"usd", " eur"})
+
+	tests := []struct {
+		name       string
+		query      string
+		wantStatus int
+		wantMethod string
+		wantPath   string
+		wantRate   float64
+		wantAmount float64
+	}{
+		{"direct", "from=NOK&to=EUR&amount=250", http.StatusOK, methodDirect, "NOK,EUR", 0.08, 20},
+		{"inverse", "from=nok&to=jpy&amount=7", http.StatusOK, methodInverse, "NOK,JPY", 1 / 0.07, 100},
+		{"pivot", "from=ZAR&to=JPY&amount=18", http.StatusOK, methodPivot, "ZAR,USD,JPY", 150.0 / 18, 150},
+		{"identity", "from=NOK&to=NOK", http.StatusOK, methodIdentity, "NOK", 1, 1},
+		{"no rate", "from=ABC&to=XYZ", http.StatusNotFound, "", "", 0, 0},
+		{"invalid currency", "from=NO&to=EUR", http.StatusBadRequest, "", "", 0, 0},
+		{"invalid amount", "from=NOK&to=EUR&amount=lots", http.StatusBadRequest, "", "", 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/countryinfo/v1/convert?"+tt.query, nil)
+			w := httptest.NewRecorder()
+
+			handler(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("expected status %d, got %d; body: %s", tt.wantStatus, w.Code, w.Body.String())
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+
+			var resp Response
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("failed to unmarshal response: %v", err)
+			}
+			if resp.Method != tt.wantMethod {
+				t.Errorf("expected method %s, got %s", tt.wantMethod, resp.Method)
+			}
+			if got := strings.Join(resp.Path, ","); got != tt.wantPath {
+				t.Errorf("expected path %s, got %s", tt.wantPath, got)
+			}
+			if math.Abs(resp.Rate-tt.wantRate) > 1e-9 {
+				t.Errorf("expected rate %f, got %f", tt.wantRate, resp.Rate)
+			}
+			if math.Abs(resp.Converted-tt.wantAmount) > 1e-9 {
+				t.Errorf("expected converted amount %f, got %f", tt.wantAmount, resp.Converted)
+			}
+			if resp.DataTimestamp.IsZero() {
+				t.Error("expected a data timestamp")
+			}
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package convert
+
+import (
+	"context"
+	"countryinfo/internal/restclient"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// Derivation methods, in the order they are tried.
+const (
+	methodIdentity = "identity"
+	methodDirect   = "direct"
+	methodInverse  = "inverse"
+	methodPivot    = "pivot"
+)
+
+// errNoRate is returned when no rate can be derived from the available tables.
+var errNoRate = errors.New("no exchange rate available")
+
+// derivation is a rate between two currencies and how it was obtained.
+type derivation struct {
+	rate   float64
+	method string
+	path   []string
+	table  *restclient.CurrencyResponse
+}
+
+// deriveRate finds the rate from one currency to another. It tries the
+// direct rate from the "from" table, then the inverse of the rate in the "to"
+// table, then each pivot currency's table, where rate = pivot→to / pivot→from.
+// When every attempt fails, the first upstream error other than not-found is
+// returned so the caller can report an outage rather than a missing rate.
+func (s *service) deriveRate(ctx context.Context, from, to string) (*derivation, error) {
+	if from == to {
+		return &derivation{rate: 1, method: methodIdentity, path: []string{from}}, nil
+	}
+
+	var upstreamErr error
+	remember := func(err error) {
+		if upstreamErr == nil && !errors.Is(err, restclient.ErrNotFound) {
+			upstreamErr = err
+		}
+	}
+
+	if table, err := s.currencies.GetExchangeRates(ctx, from); err != nil {
+		remember(err)
+	} else if rate, ok := positiveRate(table, to); ok {
+		return &derivation{rate: rate, method: methodDirect, path: []string{from, to}, table: table}, nil
+	}
+
+	if table, err := s.currencies.GetExchangeRates(ctx, to); err != nil {
+		remember(err)
+	} else if rate, ok := positiveRate(table, from); ok {
+		return &derivation{rate: 1 / rate, method: methodInverse, path: []string{from, to}, table: table}, nil
+	}
+
+	for _, pivot := range s.pivots {
+		if pivot == from || pivot == to {
+			continue
+		}
+		table, err := s.currencies.GetExchangeRates(ctx, pivot)
+		if err != nil {
+			slog.WarnContext(ctx, "failed to fetch pivot exchange rates", "error", err, "pivot", pivot)
+			remember(err)
+			continue
+		}
+		fromRate, fromOK := positiveRate(table, from)
+		toRate, toOK := positiveRate(table, to)
+		if fromOK && toOK {
+			return &derivation{rate: toRate / fromRate, method: methodPivot, path: []string{from, pivot, to}, table: table}, nil
+		}
+	}
+
+	if upstreamErr != nil {
+		return nil, upstreamErr
+	}
+	return nil, fmt.Errorf("%w from %s to %s", errNoRate, from, to)
+}
+
+func positiveRate(table *restclient.CurrencyResponse, code string) (float64, bool) {
+	rate, ok := table.Rates[code]
+	return rate, ok && rate > 0
+}
+
+// timestamp reports when the table behind the derivation was fetched; the
+// identity derivation uses no data and reports the current time.
+func (d *derivation) timestamp() time.Time {
+	if d.table == nil {
+		return time.Now().UTC()
+	}
+	return d.table.FetchedAt.UTC()
+}
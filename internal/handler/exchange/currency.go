@@ -0,0 +1,73 @@
+package exchange
+
+import (
+	"countryinfo/internal/restclient"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// allBases is the value of the base query parameter that requests one rate
+// block per currency the country uses.
+const allBases = "all"
+
+// currencyCodes returns the ISO 4217 codes of the currencies c uses, in the
+// order the exchange endpoint prefers them as base currency: the country's
+// national currency first, recognised by an ISO 4217 code that starts with the
+// country's alpha-2 code (PAB for Panama, BTN for Bhutan), then the remaining
+// codes alphabetically.
+func currencyCodes(c restclient.Country) []string {
+	codes := make([]string, 0, len(c.Currencies))
+	for code := range c.Currencies {
+		codes = append(codes, strings.ToUpper(code))
+	}
+
+	national := strings.ToUpper(c.CCA2)
+	slices.SortFunc(codes, func(a, b string) int {
+		aNational := national != "" && strings.HasPrefix(a, national)
+		bNational := national != "" && strings.HasPrefix(b, national)
+		switch {
+		case aNational && !bNational:
+			return -1
+		case bNational && !aNational:
+			return 1
+		default:
+			return strings.Compare(a, b)
+		}
+	})
+	return codes
+}
+
+// baseSelection is the base currencies chosen with the base query parameter.
+type baseSelection struct {
+	// codes are the base currencies, the primary one first.
+	codes []string
+	// all is true when every currency was requested with base=all, even if
+	// the country uses only one.
+	all bool
+}
+
+// selectBases returns the base currencies to report rates for, given the
+// value of the base query parameter: the preferred currency when it is empty,
+// every currency for "all", or the named currency if the country uses it.
+func selectBases(c restclient.Country, base string) (baseSelection, error) {
+	codes := currencyCodes(c)
+	base = strings.TrimSpace(base)
+	all := strings.EqualFold(base, allBases)
+	if len(codes) == 0 {
+		return baseSelection{all: all}, nil
+	}
+
+	switch {
+	case base == "":
+		return baseSelection{codes: codes[:1]}, nil
+	case all:
+		return baseSelection{codes: codes, all: true}, nil
+	}
+
+	base = strings.ToUpper(base)
+	if !slices.Contains(codes, base) {
+		return baseSelection{}, fmt.Errorf("invalid base currency %s: %s uses %s", base, c.Name.Common, strings.Join(codes, ", "))
+	}
+	return baseSelection{codes: []string{base}}, nil
+}
@@ -0,0 +1,71 @@
+package exchange
+
+import (
+	"countryinfo/internal/restclient"
+	"encoding/json"
+	"slices"
+	"testing"
+)
+
+func mustCountry(t *testing.T, raw string) restclient.Country {
+	t.Helper()
+	var c restclient.Country
+	if err := json.Unmarshal([]byte(raw), &c); err != nil {
+		t.Fatalf("invalid country: %v", err)
+	}
+	return c
+}
+
+func TestCurrencyCodesPrefersNationalCurrency(t *testing.T) {
+	tests := []struct {
+		name    string
+		country string
+		want    []string
+	}{
+		{"Panama", `{"cca2":"PA","currencies":{"USD":{},"PAB":{}}}`, []string{"PAB", "USD"}},
+		{"Bhutan", `{"cca2":"BT","currencies":{"INR":{},"BTN":{}}}`, []string{"BTN", "INR"}},
+		{"Zimbabwe", `{"cca2":"ZW","currencies":{"ZWL":{},"USD":{},"BWP":{}}}`, []string{"ZWL", "BWP", "USD"}},
+		{"no national currency", `{"cca2":"EC","currencies":{"USD":{}}}`, []string{"USD"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := mustCountry(t, tt.country)
+			// Map iteration order is random, so check repeatedly.
+			for range 10 {
+				if got := currencyCodes(c); !slices.Equal(got, tt.want) {
+					t.Fatalf("currencyCodes() = %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestSelectBases(t *testing.T) {
+	panama := `{"cca2":"PA","name":{"common":"Panama"},"currencies":{"USD":{},"PAB":{}}}`
+	norway := `{"cca2":"NO","name":{"common":"Norway"},"currencies":{"NOK":{}}}`
+	tests := []struct {
+		name    string
+		country string
+		base    string
+		want    []string
+		wantAll bool
+		wantErr bool
+	}{
+		{"default picks national currency", panama, "", []string{"PAB"}, false, false},
+		{"explicit base", panama, "usd", []string{"USD"}, false, false},
+		{"all bases", panama, "all", []string{"PAB", "USD"}, true, false},
+		{"all bases of a single currency", norway, "ALL", []string{"NOK"}, true, false},
+		{"currency not used by country", panama, "EUR", nil, false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := selectBases(mustCountry(t, tt.country), tt.base)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("selectBases() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !slices.Equal(got.codes, tt.want) || got.all != tt.wantAll {
+				t.Errorf("selectBases() = %v (all %t), want %v (all %t)", got.codes, got.all, tt.want, tt.wantAll)
+			}
+		})
+	}
+}
@@ -1,134 +1,290 @@
 package exchange
 
 import (
+	"context"
+	"countryinfo/internal/borders"
+	"countryinfo/internal/fanout"
+	"countryinfo/internal/resolver"
 	"countryinfo/internal/restclient"
-	"countryinfo/internal/util"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
+	"math"
 	"net/http"
 	"sort"
-	"strings"
+	"time"
 )
 
 type ExchangeResponse struct {
-	Country       string               `json:"country"`
+	Country string `json:"country"`
+	// Amount echoes the ?amount= parameter the converted amounts are based on.
+	Amount *float64 `json:"amount,omitempty"`
+	RateBlock
+	// ProximityNeighbours lists the countries added as neighbours by
+	// ?neighbours=proximity, nearest first.
+	ProximityNeighbours []ProximityNeighbour `json:"proximity-neighbours,omitempty"`
+	Warnings            []Warning            `json:"warnings,omitempty"`
+	// RateBlocks holds one block per currency the country uses when all of
+	// them are requested with ?base=all.
+	RateBlocks []RateBlock `json:"rate-blocks,omitempty"`
+}
+
+// RateBlock holds the exchange rates from one base currency to the
+// neighbours' currencies.
+type RateBlock struct {
 	BaseCurrency  string               `json:"base-currency"`
 	ExchangeRates []map[string]float64 `json:"exchange-rates"`
+	RatesSource   *RatesSource         `json:"rates-source,omitempty"`
+	// ConvertedAmounts parallels ExchangeRates when an amount is requested.
+	ConvertedAmounts []map[string]float64 `json:"converted-amounts,omitempty"`
+}
+
+// ProximityNeighbour is a country treated as a neighbour because it lies
+// within ?radius_km= of the requested country rather than across a land border.
+type ProximityNeighbour struct {
+	Alpha3     string  `json:"alpha3"`
+	Name       string  `json:"name"`
+	DistanceKm float64 `json:"distance-km"`
+}
+
+// RatesSource describes how old the exchange rates are and where they came from.
+type RatesSource struct {
+	FetchedAt  time.Time `json:"fetched-at"`
+	AgeSeconds int       `json:"age-seconds"`
+	Cached     bool      `json:"cached"`
+	Stale      bool      `json:"stale"`
+}
+
+func newRatesSource(rates *restclient.CurrencyResponse) *RatesSource {
+	return &RatesSource{
+		FetchedAt:  rates.FetchedAt.UTC(),
+		AgeSeconds: int(rates.Age().Seconds()),
+		Cached:     rates.FromCache,
+		Stale:      rates.Stale,
+	}
 }
 
 type service struct {
-	countries  *restclient.CountriesClient
-	currencies *restclient.CurrencyClient
+	countries   *restclient.CountriesClient
+	currencies  *restclient.CurrencyClient
+	resolver    *resolver.Resolver
+	graph       *borders.Loader
+	concurrency int
 }
 
-func Handler(countries *restclient.CountriesClient, currencies *restclient.CurrencyClient) http.HandlerFunc {
-	s := &service{
-		countries:  countries,
-		currencies: currencies,
+// Option configures optional exchange handler behaviour.
+type Option func(*service)
+
+// WithConcurrency bounds how many neighbour lookups run in parallel when they
+// cannot be resolved with a single batch request.
+func WithConcurrency(n int) Option {
+	return func(s *service) {
+		s.concurrency = n
 	}
-	return s.exchangeHandler
 }
 
-func (s *service) exchangeHandler(w http.ResponseWriter, r *http.Request) {
-	countryCode := strings.ToLower(strings.TrimSpace(r.PathValue("country_code")))
-	if !util.IsTwoLetterCountryCode(countryCode) {
-		http.Error(
-			w,
-			fmt.Sprintf("%s\ninvalid country code: %s", http.StatusText(http.StatusBadRequest), countryCode),
-			http.StatusBadRequest,
-		)
-		return
+func newService(countries *restclient.CountriesClient, currencies *restclient.CurrencyClient, countryResolver *resolver.Resolver, borderGraph *borders.Loader, opts []Option) *service {
+	s := &service{
+		countries:   countries,
+		currencies:  currencies,
+		resolver:    countryResolver,
+		graph:       borderGraph,
+		concurrency: fanout.DefaultLimit,
 	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
 
-	ctx := r.Context()
+func Handler(countries *restclient.CountriesClient, currencies *restclient.CurrencyClient, countryResolver *resolver.Resolver, borderGraph *borders.Loader, opts ...Option) http.HandlerFunc {
+	return newService(countries, currencies, countryResolver, borderGraph, opts).exchangeHandler
+}
 
-	// 1. Look up the input country to get its currency and borders.
-	countries, err := s.countries.GetByAlpha(ctx, countryCode)
+func (s *service) exchangeHandler(w http.ResponseWriter, r *http.Request) {
+	opts, err := parseTargetOptions(r.URL.Query())
 	if err != nil {
-		slog.ErrorContext(ctx, "failed to look up country", "error", err, "country_code", countryCode)
-		http.Error(w, "failed to look up country", http.StatusBadGateway)
-		return
-	}
-	if len(countries) == 0 {
-		http.Error(w, "country not found", http.StatusNotFound)
+		http.Error(w, fmt.Sprintf("%s\n%s", http.StatusText(http.StatusBadRequest), err), http.StatusBadRequest)
 		return
 	}
-	country := countries[0]
 
-	// Extract the base currency code (first key in the currencies map).
-	baseCurrencyCode := firstCurrencyCode(country)
-	if baseCurrencyCode == "" {
-		http.Error(w, "no currency found for country", http.StatusNotFound)
+	country, bases, ok := s.lookupCountry(w, r)
+	if !ok {
 		return
 	}
+	ctx := r.Context()
 
-	if len(country.Borders) == 0 {
-		// Country has no land borders, return empty exchange rates.
-		writeJSON(w, r, ExchangeResponse{
-			Country:       country.Name.Common,
-			BaseCurrency:  baseCurrencyCode,
-			ExchangeRates: []map[string]float64{},
-		})
-		return
+	// Collect the target currencies: those of the bordering countries, and
+	// of nearby countries with ?neighbours=proximity, mapped to the
+	// neighbours using them, and any explicitly requested with ?to=.
+	targets := make(map[string][]string)
+	var warnings []Warning
+	var neighbours []borders.Neighbour
+	if opts.includeNeighbours && len(country.Borders) > 0 {
+		neighbours, warnings = s.resolveNeighbours(ctx, country.Borders)
 	}
-
-	// 2. Look up each bordering country to collect their currency codes.
-	neighbourCurrencies := make(map[string]struct{})
-	for _, borderCode := range country.Borders {
-		neighbours, err := s.countries.GetByAlpha(ctx, strings.ToLower(borderCode))
-		if err != nil {
-			slog.WarnContext(ctx, "failed to look up border country", "error", err, "border_code", borderCode)
-			continue
+	var proximity []ProximityNeighbour
+	if opts.proximity {
+		nearby, proximityWarnings := s.nearbyNeighbours(ctx, country, opts.radiusKm)
+		for _, n := range nearby {
+			neighbours = append(neighbours, borders.Neighbour{Code: n.Code, Country: n.Country})
+			proximity = append(proximity, ProximityNeighbour{
+				Alpha3:     n.Code,
+				Name:       n.Country.Name.Common,
+				DistanceKm: math.Round(n.DistanceKm*10) / 10,
+			})
 		}
-		if len(neighbours) == 0 {
+		warnings = append(warnings, proximityWarnings...)
+	}
+	for _, n := range neighbours {
+		if len(n.Country.Currencies) == 0 {
+			warnings = append(warnings, Warning{Neighbour: n.Code, Reason: "neighbour has no currency"})
 			continue
 		}
-		for code := range neighbours[0].Currencies {
-			neighbourCurrencies[code] = struct{}{}
+		for code := range n.Country.Currencies {
+			targets[code] = append(targets[code], n.Code)
+		}
+	}
+	for _, code := range opts.currencies {
+		if _, ok := targets[code]; !ok {
+			targets[code] = nil
 		}
 	}
 
-	// 3. Fetch exchange rates for the base currency.
-	rates, err := s.currencies.GetExchangeRates(ctx, baseCurrencyCode)
-	if err != nil {
-		slog.ErrorContext(ctx, "failed to fetch exchange rates", "error", err, "base_currency", baseCurrencyCode)
-		http.Error(w, "failed to fetch exchange rates", http.StatusBadGateway)
+	if len(targets) == 0 {
+		// Nothing to quote, e.g. a country without land borders: return
+		// empty exchange rates without contacting the currency API.
+		resp := ExchangeResponse{
+			Country:             country.Name.Common,
+			RateBlock:           RateBlock{BaseCurrency: bases.codes[0], ExchangeRates: []map[string]float64{}},
+			Amount:              opts.amount,
+			ProximityNeighbours: proximity,
+			Warnings:            warnings,
+		}
+		if bases.all {
+			for _, base := range bases.codes {
+				resp.RateBlocks = append(resp.RateBlocks, RateBlock{BaseCurrency: base, ExchangeRates: []map[string]float64{}})
+			}
+		}
+		if len(warnings) > 0 {
+			w.Header().Set(PartialResultHeader, "true")
+		}
+		writeJSON(w, r, resp)
 		return
 	}
 
-	// 4. Filter rates to only include neighboring countries' currencies.
-	codes := make([]string, 0, len(neighbourCurrencies))
-	for code := range neighbourCurrencies {
-		if _, ok := rates.Rates[code]; ok {
-			codes = append(codes, code)
+	// Fetch exchange rates for each base currency. The first base is the
+	// primary one: failing to get its rates fails the request.
+	resp := ExchangeResponse{Country: country.Name.Common, Amount: opts.amount, ProximityNeighbours: proximity}
+	for i, base := range bases.codes {
+		block, blockWarnings, err := s.rateBlock(ctx, base, targets, opts.amount)
+		if err != nil && i == 0 {
+			if errors.Is(err, restclient.ErrNotFound) {
+				http.Error(w, fmt.Sprintf("no exchange rates for currency %s", base), http.StatusNotFound)
+				return
+			}
+			slog.ErrorContext(ctx, "failed to fetch exchange rates", "error", err, "base_currency", base)
+			http.Error(w, restclient.ErrorMessage(err), restclient.HTTPStatus(err))
+			return
+		}
+		if err != nil {
+			slog.WarnContext(ctx, "failed to fetch exchange rates", "error", err, "base_currency", base)
+			warnings = append(warnings, Warning{Currency: base, Reason: borders.FailureReason(err)})
+			continue
 		}
-	}
-	sort.Strings(codes)
 
-	exchangeRates := make([]map[string]float64, 0, len(codes))
-	for _, code := range codes {
-		exchangeRates = append(exchangeRates, map[string]float64{code: rates.Rates[code]})
+		warnings = append(warnings, blockWarnings...)
+		if i == 0 {
+			resp.RateBlock = block
+		}
+		if bases.all {
+			resp.RateBlocks = append(resp.RateBlocks, block)
+		}
 	}
+	resp.Warnings = warnings
 
-	writeJSON(w, r, ExchangeResponse{
-		Country:       country.Name.Common,
-		BaseCurrency:  baseCurrencyCode,
-		ExchangeRates: exchangeRates,
-	})
+	if len(warnings) > 0 {
+		w.Header().Set(PartialResultHeader, "true")
+	}
+	writeJSON(w, r, resp)
 
 	slog.InfoContext(ctx, "exchange request completed",
-		"country_code", countryCode,
-		"base_currency", baseCurrencyCode,
-		"neighbour_currencies", len(exchangeRates),
+		"country_code", country.CCA2,
+		"base_currency", resp.BaseCurrency,
+		"neighbour_currencies", len(resp.ExchangeRates),
+		"warnings", len(warnings),
+		"rates_cached", resp.RatesSource.Cached,
+		"rates_stale", resp.RatesSource.Stale,
 	)
 }
 
-func firstCurrencyCode(c restclient.Country) string {
-	for code := range c.Currencies {
-		return code
+// lookupCountry resolves the requested country from its code or name and
+// selects its base currencies from the ?base= parameter. It writes an error
+// response and returns false if any step fails.
+func (s *service) lookupCountry(w http.ResponseWriter, r *http.Request) (restclient.Country, baseSelection, bool) {
+	query := r.PathValue("country_code")
+	country, err := s.resolver.Resolve(r.Context(), query)
+	if err != nil {
+		resolver.WriteError(w, r, query, err)
+		return restclient.Country{}, baseSelection{}, false
+	}
+
+	// Pick the base currencies deterministically, or as requested with ?base=.
+	bases, err := selectBases(country, r.URL.Query().Get("base"))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("%s\n%s", http.StatusText(http.StatusBadRequest), err), http.StatusBadRequest)
+		return restclient.Country{}, baseSelection{}, false
+	}
+	if len(bases.codes) == 0 {
+		http.Error(w, "no currency found for country", http.StatusNotFound)
+		return restclient.Country{}, baseSelection{}, false
+	}
+	return country, bases, true
+}
+
+// rateBlock fetches the rates for base and keeps those of the target
+// currencies, sorted by currency code. targets maps each currency to the
+// neighbours using it, if any; a warning is returned for every target
+// currency that has no rate from base. If amount is set, it is also converted
+// into every target currency.
+func (s *service) rateBlock(ctx context.Context, base string, targets map[string][]string, amount *float64) (RateBlock, []Warning, error) {
+	rates, err := s.currencies.GetExchangeRates(ctx, base)
+	if err != nil {
+		return RateBlock{}, nil, err
+	}
+
+	codes := make([]string, 0, len(targets))
+	for code := range targets {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+
+	var warnings []Warning
+	block := RateBlock{
+		BaseCurrency:  base,
+		ExchangeRates: make([]map[string]float64, 0, len(codes)),
+		RatesSource:   newRatesSource(rates),
+	}
+	for _, code := range codes {
+		rate, ok := rates.Rates[code]
+		if !ok {
+			reason := fmt.Sprintf("no exchange rate from %s", base)
+			if len(targets[code]) == 0 {
+				warnings = append(warnings, Warning{Currency: code, Reason: reason})
+			}
+			for _, neighbourCode := range targets[code] {
+				warnings = append(warnings, Warning{Neighbour: neighbourCode, Currency: code, Reason: reason})
+			}
+			continue
+		}
+		block.ExchangeRates = append(block.ExchangeRates, map[string]float64{code: rate})
+		if amount != nil {
+			block.ConvertedAmounts = append(block.ConvertedAmounts, map[string]float64{code: *amount * rate})
+		}
 	}
-	return ""
+
+	return block, warnings, nil
 }
 
 func writeJSON(w http.ResponseWriter, r *http.Request, v any) {
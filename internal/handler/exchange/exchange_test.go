@@ -1,22 +1,41 @@
 package exchange
 
 import (
+	"context"
+	"countryinfo/internal/borders"
+	"countryinfo/internal/resolver"
 	"countryinfo/internal/restclient"
+	"countryinfo/internal/restclient/restclienttest"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 )
 
+// newHandler builds the v1 handler with a resolver and border graph of its
+// own, as the router would share them between handlers.
+func newHandler(countries *restclient.CountriesClient, currencies *restclient.CurrencyClient, opts ...Option) http.HandlerFunc {
+	return Handler(countries, currencies, resolver.New(countries), borders.NewLoader(countries), opts...)
+}
+
+// newHandlerV2 is newHandler for the v2 handler.
+func newHandlerV2(countries *restclient.CountriesClient, currencies *restclient.CurrencyClient, opts ...Option) http.HandlerFunc {
+	return HandlerV2(countries, currencies, resolver.New(countries), borders.NewLoader(countries), opts...)
+}
+
 func TestExchangeHandlerRejectsInvalidCountryCode(t *testing.T) {
 	t.Parallel()
 
 	countries := restclient.NewCountriesClient("http://example.com")
 	currencies := restclient.NewCurrencyClient("http://example.com")
-	handler := Handler(countries, currencies)
+	handler := newHandler(countries, currencies)
 
-	req := httptest.NewRequest(http.MethodGet, "/countryinfo/v1/exchange/nor", nil)
-	req.SetPathValue("country_code", "nor")
+	req := httptest.NewRequest(http.MethodGet, "/countryinfo/v1/exchange/n%3Cr%3E", nil)
+	req.SetPathValue("country_code", "n<r>")
 	w := httptest.NewRecorder()
 
 	handler(w, req)
@@ -30,21 +49,11 @@ func TestExchangeHandlerReturnsNeighbourRates(t *testing.T) {
 	t.Parallel()
 
 	// Mock countries API: serves Norway and its neighbours.
-	countriesAPI := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		responses := map[string]string{
-			"/v3.1/alpha/no":  `[{"name":{"common":"Norway"},"currencies":{"NOK":{"name":"Norwegian Krone","symbol":"kr"}},"borders":["FIN","SWE"],"capital":["Oslo"],"continents":["Europe"],"population":5379475,"area":323802,"languages":{"nob":"Norwegian Bokmal"},"flags":{"png":"","svg":"","alt":""}}]`,
-			"/v3.1/alpha/fin": `[{"name":{"common":"Finland"},"currencies":{"EUR":{"name":"Euro","symbol":"€"}},"borders":["NOR","SWE","RUS"],"capital":["Helsinki"],"continents":["Europe"],"population":5530719,"area":338424,"languages":{"fin":"Finnish"},"flags":{"png":"","svg":"","alt":""}}]`,
-			"/v3.1/alpha/swe": `[{"name":{"common":"Sweden"},"currencies":{"SEK":{"name":"Swedish Krona","symbol":"kr"}},"borders":["NOR","FIN","DNK"],"capital":["Stockholm"],"continents":["Europe"],"population":10353442,"area":450295,"languages":{"swe":"Swedish"},"flags":{"png":"","svg":"","alt":""}}]`,
-		}
-
-		w.Header().Set("Content-Type", "application/json")
-		if body, ok := responses[r.URL.Path]; ok {
-			_, _ = w.Write([]byte(body))
-		} else {
-			w.WriteHeader(http.StatusNotFound)
-			_, _ = w.Write([]byte(`{"status":404,"message":"Not Found"}`))
-		}
-	}))
+	countriesAPI := restclienttest.NewCountriesAPI(t, []string{
+		`{"cca2":"NO","cca3":"NOR","name":{"common":"Norway"},"currencies":{"NOK":{"name":"Norwegian Krone","symbol":"kr"}},"borders":["FIN","SWE"],"capital":["Oslo"],"continents":["Europe"],"population":5379475,"area":323802,"languages":{"nob":"Norwegian Bokmal"},"flags":{"png":"","svg":"","alt":""}}`,
+		`{"cca2":"FI","cca3":"FIN","name":{"common":"Finland"},"currencies":{"EUR":{"name":"Euro","symbol":"€"}},"borders":["NOR","SWE","RUS"],"capital":["Helsinki"],"continents":["Europe"],"population":5530719,"area":338424,"languages":{"fin":"Finnish"},"flags":{"png":"","svg":"","alt":""}}`,
+		`{"cca2":"SE","cca3":"SWE","name":{"common":"Sweden"},"currencies":{"SEK":{"name":"Swedish Krona","symbol":"kr"}},"borders":["NOR","FIN","DNK"],"capital":["Stockholm"],"continents":["Europe"],"population":10353442,"area":450295,"languages":{"swe":"Swedish"},"flags":{"png":"","svg":"","alt":""}}`,
+	})
 	defer countriesAPI.Close()
 
 	// Mock currency API: serves NOK exchange rates.
@@ -56,7 +65,7 @@ func TestExchangeHandlerReturnsNeighbourRates(t *testing.T) {
 
 	countries := restclient.NewCountriesClient(countriesAPI.URL + "/v3.1")
 	currencies := restclient.NewCurrencyClient(currencyAPI.URL + "/currency")
-	handler := Handler(countries, currencies)
+	handler := newHandler(countries, currencies)
 
 	req := httptest.NewRequest(http.MethodGet, "/countryinfo/v1/exchange/no", nil)
 	req.SetPathValue("country_code", "no")
@@ -99,6 +108,9 @@ func TestExchangeHandlerReturnsNeighbourRates(t *testing.T) {
 	if _, ok := got["USD"]; ok {
 		t.Error("USD should not be in exchange rates (not a neighbour currency)")
 	}
+	if resp.RatesSource == nil || resp.RatesSource.Cached || resp.RatesSource.Stale {
+		t.Errorf("expected fresh upstream rates source, got %+v", resp.RatesSource)
+	}
 }
 
 func TestExchangeHandlerCountryWithNoBorders(t *testing.T) {
@@ -119,7 +131,7 @@ func TestExchangeHandlerCountryWithNoBorders(t *testing.T) {
 
 	countries := restclient.NewCountriesClient(countriesAPI.URL + "/v3.1")
 	currencies := restclient.NewCurrencyClient(currencyAPI.URL + "/currency")
-	handler := Handler(countries, currencies)
+	handler := newHandler(countries, currencies)
 
 	req := httptest.NewRequest(http.MethodGet, "/countryinfo/v1/exchange/is", nil)
 	req.SetPathValue("country_code", "is")
@@ -143,3 +155,490 @@ func TestExchangeHandlerCountryWithNoBorders(t *testing.T) {
 		t.Errorf("expected empty exchange rates, got %v", resp.ExchangeRates)
 	}
 }
+
+// newSlowCountriesAPI starts a mock REST Countries API that rejects batch
+// lookups and answers every single lookup after delay. The country with code
+// "ctr" borders the countries n01..nNN, each using its own currency.
+func newSlowCountriesAPI(neighbours int, delay time.Duration) *httptest.Server {
+	borders := make([]string, neighbours)
+	for i := range borders {
+		borders[i] = fmt.Sprintf(`"N%02d"`, i+1)
+	}
+	centre := `[{"cca2":"CT","cca3":"CTR","name":{"common":"Centre"},"currencies":{"CTR":{}},"borders":[` + strings.Join(borders, ",") + `]}]`
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v3.1/alpha" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		select {
+		case <-time.After(delay):
+		case <-r.Context().Done():
+			return
+		}
+
+		code := strings.ToUpper(strings.TrimPrefix(r.URL.Path, "/v3.1/alpha/"))
+		w.Header().Set("Content-Type", "application/json")
+		if code == "CT" {
+			_, _ = w.Write([]byte(centre))
+			return
+		}
+		_, _ = fmt.Fprintf(w, `[{"cca3":%q,"name":{"common":%q},"currencies":{%q:{}}}]`, code, code, "C"+code[1:])
+	}))
+}
+
+func newRatesAPI(neighbours int) *httptest.Server {
+	rates := make([]string, neighbours)
+	for i := range rates {
+		rates[i] = fmt.Sprintf(`"C%02d":%d`, i+1, i+1)
+	}
+	body := `{"base_code":"CTR","rates":{` + strings.Join(rates, ",") + `}}`
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(body))
+	}))
+}
+
+func TestExchangeHandlerFallsBackToConcurrentLookups(t *testing.T) {
+	t.Parallel()
+
+	const neighbours = 14
+	countriesAPI := newSlowCountriesAPI(neighbours, 5*time.Millisecond)
+	defer countriesAPI.Close()
+	currencyAPI := newRatesAPI(neighbours)
+	defer currencyAPI.Close()
+
+	handler := newHandler(
+		restclient.NewCountriesClient(countriesAPI.URL+"/v3.1"),
+		restclient.NewCurrencyClient(currencyAPI.URL),
+		WithConcurrency(4),
+	)
+
+	req := httptest.NewRequest(http.MethodGet, "/countryinfo/v1/exchange/ct", nil)
+	req.SetPathValue("country_code", "ct")
+	w := httptest.NewRecorder()
+
+	handler(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d; body: %s", w.Code, w.Body.String())
+	}
+
+	var resp ExchangeResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+	if len(resp.ExchangeRates) != neighbours {
+		t.Fatalf("expected %d exchange rates, got %d", neighbours, len(resp.ExchangeRates))
+	}
+	for i, entry := range resp.ExchangeRates {
+		code := fmt.Sprintf("C%02d", i+1)
+		if _, ok := entry[code]; !ok {
+			t.Errorf("expected entry %d to be %s, got %v", i, code, entry)
+		}
+	}
+}
+
+func TestExchangeHandlerCancelsUpstreamLookupsWhenClientLeaves(t *testing.T) {
+	t.Parallel()
+
+	const neighbours = 3
+	var started, cancelled sync.WaitGroup
+	started.Add(neighbours)
+	cancelled.Add(neighbours)
+	countriesAPI := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v3.1/alpha":
+			w.WriteHeader(http.StatusBadRequest)
+		case "/v3.1/alpha/ct":
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`[{"cca2":"CT","cca3":"CTR","name":{"common":"Centre"},"currencies":{"CTR":{}},"borders":["N01","N02","N03"]}]`))
+		default:
+			// Neighbour lookups hang until the client gives up on them.
+			started.Done()
+			<-r.Context().Done()
+			cancelled.Done()
+		}
+	}))
+	defer countriesAPI.Close()
+	currencyAPI := newRatesAPI(neighbours)
+	defer currencyAPI.Close()
+
+	handler := newHandler(
+		restclient.NewCountriesClient(countriesAPI.URL+"/v3.1"),
+		restclient.NewCurrencyClient(currencyAPI.URL),
+	)
+	ctx, cancel := context.WithCancel(context.Background())
+	req := httptest.NewRequestWithContext(ctx, http.MethodGet, "/countryinfo/v1/exchange/ct", nil)
+	req.SetPathValue("country_code", "ct")
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		handler(httptest.NewRecorder(), req)
+	}()
+
+	started.Wait()
+	cancel()
+	<-done
+
+	upstreamCancelled := make(chan struct{})
+	go func() {
+		cancelled.Wait()
+		close(upstreamCancelled)
+	}()
+	select {
+	case <-upstreamCancelled:
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected the neighbour lookups to be cancelled upstream")
+	}
+}
+
+// BenchmarkExchangeHandlerNeighbourConcurrency shows how neighbour lookup
+// latency scales with the concurrency limit against a slow upstream.
+func BenchmarkExchangeHandlerNeighbourConcurrency(b *testing.B) {
+	const neighbours = 14
+	countriesAPI := newSlowCountriesAPI(neighbours, 10*time.Millisecond)
+	defer countriesAPI.Close()
+	currencyAPI := newRatesAPI(neighbours)
+	defer currencyAPI.Close()
+
+	for _, concurrency := range []int{1, 4, 8, 16} {
+		b.Run(fmt.Sprintf("concurrency=%d", concurrency), func(b *testing.B) {
+			handler := newHandler(
+				restclient.NewCountriesClient(countriesAPI.URL+"/v3.1", restclient.WithCountriesCache(0, 0, 0)),
+				restclient.NewCurrencyClient(currencyAPI.URL),
+				WithConcurrency(concurrency),
+			)
+
+			for b.Loop() {
+				req := httptest.NewRequest(http.MethodGet, "/countryinfo/v1/exchange/ct", nil)
+				req.SetPathValue("country_code", "ct")
+				w := httptest.NewRecorder()
+
+				handler(w, req)
+
+				if w.Code != http.StatusOK {
+					b.Fatalf("expected status 200, got %d", w.Code)
+				}
+			}
+		})
+	}
+}
+
+func TestExchangeHandlerReportsPartialResults(t *testing.T) {
+	t.Parallel()
+
+	countriesAPI := restclienttest.NewCountriesAPI(t, []string{
+		`{"cca2":"NO","cca3":"NOR","name":{"common":"Norway"},"currencies":{"NOK":{}},"borders":["FIN","SWE","RUS"]}`,
+		`{"cca2":"FI","cca3":"FIN","name":{"common":"Finland"},"currencies":{"EUR":{}}}`,
+		`{"cca2":"SE","cca3":"SWE","name":{"common":"Sweden"},"currencies":{"SEK":{}}}`,
+	})
+	defer countriesAPI.Close()
+
+	currencyAPI := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"base_code":"NOK","rates":{"SEK":0.914075}}`))
+	}))
+	defer currencyAPI.Close()
+
+	handler := newHandler(
+		restclient.NewCountriesClient(countriesAPI.URL+"/v3.1"),
+		restclient.NewCurrencyClient(currencyAPI.URL),
+	)
+
+	req := httptest.NewRequest(http.MethodGet, "/countryinfo/v1/exchange/no", nil)
+	req.SetPathValue("country_code", "no")
+	w := httptest.NewRecorder()
+
+	handler(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d; body: %s", w.Code, w.Body.String())
+	}
+	if got := w.Header().Get(PartialResultHeader); got != "true" {
+		t.Errorf("expected %s header to be true, got %q", PartialResultHeader, got)
+	}
+
+	var resp ExchangeResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+	if len(resp.ExchangeRates) != 1 {
+		t.Fatalf("expected 1 exchange rate (SEK), got %v", resp.ExchangeRates)
+	}
+
+	want := []Warning{
+		{Neighbour: "RUS", Reason: "country not found"},
+		{Neighbour: "FIN", Currency: "EUR", Reason: "no exchange rate from NOK"},
+	}
+	if len(resp.Warnings) != len(want) {
+		t.Fatalf("expected warnings %v, got %v", want, resp.Warnings)
+	}
+	for i := range want {
+		if resp.Warnings[i] != want[i] {
+			t.Errorf("warning %d = %+v, want %+v", i, resp.Warnings[i], want[i])
+		}
+	}
+}
+
+func TestExchangeHandlerBaseCurrencySelection(t *testing.T) {
+	t.Parallel()
+
+	countriesAPI := restclienttest.NewCountriesAPI(t, []string{
+		`{"cca2":"PA","cca3":"PAN","name":{"common":"Panama"},"currencies":{"USD":{},"PAB":{}},"borders":["COL"]}`,
+		`{"cca2":"CO","cca3":"COL","name":{"common":"Colombia"},"currencies":{"COP":{}}}`,
+	})
+	defer countriesAPI.Close()
+
+	currencyAPI := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		base := strings.TrimPrefix(r.URL.Path, "/")
+		_, _ = fmt.Fprintf(w, `{"base_code":%q,"rates":{"COP":%d}}`, base, len(base))
+	}))
+	defer currencyAPI.Close()
+
+	handler := newHandler(
+		restclient.NewCountriesClient(countriesAPI.URL+"/v3.1"),
+		restclient.NewCurrencyClient(currencyAPI.URL),
+	)
+
+	tests := []struct {
+		query      string
+		wantStatus int
+		wantBase   string
+		wantBlocks []string
+	}{
+		{"", http.StatusOK, "PAB", nil},
+		{"?base=usd", http.StatusOK, "USD", nil},
+		{"?base=all", http.StatusOK, "PAB", []string{"PAB", "USD"}},
+		{"?base=EUR", http.StatusBadRequest, "", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/countryinfo/v1/exchange/pa"+tt.query, nil)
+			req.SetPathValue("country_code", "pa")
+			w := httptest.NewRecorder()
+
+			handler(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("expected status %d, got %d; body: %s", tt.wantStatus, w.Code, w.Body.String())
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+
+			var resp ExchangeResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("failed to unmarshal response: %v", err)
+			}
+			if resp.BaseCurrency != tt.wantBase {
+				t.Errorf("expected base currency %s, got %s", tt.wantBase, resp.BaseCurrency)
+			}
+			var blocks []string
+			for _, block := range resp.RateBlocks {
+				blocks = append(blocks, block.BaseCurrency)
+			}
+			if strings.Join(blocks, ",") != strings.Join(tt.wantBlocks, ",") {
+				t.Errorf("expected rate blocks %v, got %v", tt.wantBlocks, blocks)
+			}
+		})
+	}
+}
+
+func TestExchangeHandlerCustomTargets(t *testing.T) {
+	t.Parallel()
+
+	countriesAPI := restclienttest.NewCountriesAPI(t, []string{
+		`{"cca2":"NO","cca3":"NOR","name":{"common":"Norway"},"currencies":{"NOK":{}},"borders":["SWE"]}`,
+		`{"cca2":"SE","cca3":"SWE","name":{"common":"Sweden"},"currencies":{"SEK":{}}}`,
+	})
+	defer countriesAPI.Close()
+
+	currencyAPI := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"base_code":"NOK","rates":{"SEK":0.9,"USD":0.1,"EUR":0.08}}`))
+	}))
+	defer currencyAPI.Close()
+
+	handler := newHandler(
+		restclient.NewCountriesClient(countriesAPI.URL+"/v3.1"),
+		restclient.NewCurrencyClient(currencyAPI.URL),
+	)
+
+	tests := []struct {
+		name          string
+		query         string
+		wantStatus    int
+		wantRates     string
+		wantConverted string
+		wantWarnings  int
+	}{
+		{"targets replace neighbours", "?to=usd,EUR", http.StatusOK, "EUR,USD", "", 0},
+		{"targets in addition to neighbours", "?to=USD&neighbours=true", http.StatusOK, "SEK,USD", "", 0},
+		{"amount is converted", "?to=USD&amount=250", http.StatusOK, "USD", "USD=25", 0},
+		{"unknown target is a warning", "?to=USD,XYZ", http.StatusOK, "USD", "", 1},
+		{"invalid target", "?to=US", http.StatusBadRequest, "", "", 0},
+		{"invalid amount", "?amount=abc", http.StatusBadRequest, "", "", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/countryinfo/v1/exchange/no"+tt.query, nil)
+			req.SetPathValue("country_code", "no")
+			w := httptest.NewRecorder()
+
+			handler(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("expected status %d, got %d; body: %s", tt.wantStatus, w.Code, w.Body.String())
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+
+			var resp ExchangeResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("failed to unmarshal response: %v", err)
+			}
+
+			var rates, converted []string
+			for _, entry := range resp.ExchangeRates {
+				for code := range entry {
+					rates = append(rates, code)
+				}
+			}
+			for _, entry := range resp.ConvertedAmounts {
+				for code, value := range entry {
+					converted = append(converted, fmt.Sprintf("%s=%g", code, value))
+				}
+			}
+			if got := strings.Join(rates, ","); got != tt.wantRates {
+				t.Errorf("expected rates for %s, got %s", tt.wantRates, got)
+			}
+			if got := strings.Join(converted, ","); got != tt.wantConverted {
+				t.Errorf("expected converted amounts %s, got %s", tt.wantConverted, got)
+			}
+			if len(resp.Warnings) != tt.wantWarnings {
+				t.Errorf("expected %d warnings, got %v", tt.wantWarnings, resp.Warnings)
+			}
+		})
+	}
+}
+
+func TestExchangeHandlerProximityNeighbours(t *testing.T) {
+	t.Parallel()
+
+	countriesAPI := restclienttest.NewCountriesAPI(t, []string{
+		`{"cca2":"IS","cca3":"ISL","name":{"common":"Iceland"},"currencies":{"ISK":{}},"latlng":[65,-18],"capitalInfo":{"latlng":[64.15,-21.95]}}`,
+		`{"cca2":"FO","cca3":"FRO","name":{"common":"Faroe Islands"},"currencies":{"DKK":{}},"latlng":[62,-7],"capitalInfo":{"latlng":[62.01,-6.77]}}`,
+		`{"cca2":"GL","cca3":"GRL","name":{"common":"Greenland"},"currencies":{"DKK":{}},"latlng":[72,-40],"capitalInfo":{"latlng":[64.18,-51.75]}}`,
+		`{"cca2":"NO","cca3":"NOR","name":{"common":"Norway"},"currencies":{"NOK":{}},"latlng":[62,10],"capitalInfo":{"latlng":[59.92,10.75]}}`,
+		`{"cca2":"AQ","cca3":"ATA","name":{"common":"Antarctica"},"latlng":[-90,0]}`,
+	})
+	defer countriesAPI.Close()
+
+	currencyAPI := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"base_code":"ISK","rates":{"DKK":0.05,"NOK":0.08}}`))
+	}))
+	defer currencyAPI.Close()
+
+	handler := newHandler(
+		restclient.NewCountriesClient(countriesAPI.URL+"/v3.1"),
+		restclient.NewCurrencyClient(currencyAPI.URL),
+	)
+
+	tests := []struct {
+		name          string
+		query         string
+		wantStatus    int
+		wantRates     string
+		wantProximity string
+	}{
+		{"land borders only", "", http.StatusOK, "", ""},
+		{"default radius", "?neighbours=proximity", http.StatusOK, "DKK", "FRO=798.2"},
+		{"wider radius", "?neighbours=proximity&radius_km=1500", http.StatusOK, "DKK", "FRO=798.2,GRL=1430.8"},
+		{"radius reaches Norway", "?neighbours=proximity&radius_km=1800", http.StatusOK, "DKK,NOK", "FRO=798.2,GRL=1430.8,NOR=1747"},
+		{"countries without currency skipped", "?neighbours=proximity&radius_km=20000", http.StatusOK, "DKK,NOK", "FRO=798.2,GRL=1430.8,NOR=1747"},
+		{"radius without proximity", "?radius_km=1500", http.StatusBadRequest, "", ""},
+		{"invalid radius", "?neighbours=proximity&radius_km=-5", http.StatusBadRequest, "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/countryinfo/v1/exchange/is"+tt.query, nil)
+			req.SetPathValue("country_code", "is")
+			w := httptest.NewRecorder()
+
+			handler(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("expected status %d, got %d; body: %s", tt.wantStatus, w.Code, w.Body.String())
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+
+			var resp ExchangeResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("failed to unmarshal response: %v", err)
+			}
+			var rates, proximity []string
+			for _, entry := range resp.ExchangeRates {
+				for code := range entry {
+					rates = append(rates, code)
+				}
+			}
+			for _, n := range resp.ProximityNeighbours {
+				proximity = append(proximity, fmt.Sprintf("%s=%g", n.Alpha3, n.DistanceKm))
+			}
+			if got := strings.Join(rates, ","); got != tt.wantRates {
+				t.Errorf("expected rates for %q, got %q", tt.wantRates, got)
+			}
+			if got := strings.Join(proximity, ","); got != tt.wantProximity {
+				t.Errorf("expected proximity neighbours %q, got %q", tt.wantProximity, got)
+			}
+			if len(resp.Warnings) != 0 {
+				t.Errorf("expected no warnings, got %v", resp.Warnings)
+			}
+		})
+	}
+}
+
+func TestExchangeHandlerAllBasesOfSingleCurrency(t *testing.T) {
+	t.Parallel()
+
+	countriesAPI := restclienttest.NewCountriesAPI(t, []string{
+		`{"cca2":"NO","cca3":"NOR","name":{"common":"Norway"},"currencies":{"NOK":{}},"borders":["SWE"]}`,
+		`{"cca2":"SE","cca3":"SWE","name":{"common":"Sweden"},"currencies":{"SEK":{}}}`,
+	})
+	defer countriesAPI.Close()
+
+	currencyAPI := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"base_code":"NOK","rates":{"SEK":0.9}}`))
+	}))
+	defer currencyAPI.Close()
+
+	handler := newHandler(
+		restclient.NewCountriesClient(countriesAPI.URL+"/v3.1"),
+		restclient.NewCurrencyClient(currencyAPI.URL),
+	)
+	req := httptest.NewRequest(http.MethodGet, "/countryinfo/v1/exchange/no?base=all", nil)
+	req.SetPathValue("country_code", "no")
+	w := httptest.NewRecorder()
+
+	handler(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d; body: %s", w.Code, w.Body.String())
+	}
+	var resp ExchangeResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+	if len(resp.RateBlocks) != 1 || resp.RateBlocks[0].BaseCurrency != "NOK" {
+		t.Fatalf("expected a single NOK rate block, got %+v", resp.RateBlocks)
+	}
+}
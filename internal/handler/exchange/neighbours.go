@@ -0,0 +1,56 @@
+package exchange
+
+import (
+	"context"
+	"countryinfo/internal/borders"
+	"countryinfo/internal/handler/partial"
+	"countryinfo/internal/restclient"
+	"log/slog"
+	"slices"
+	"strings"
+)
+
+// PartialResultHeader is set on exchange responses that omit some neighbours
+// or currencies; the reasons are listed in the response's warnings.
+const PartialResultHeader = partial.Header
+
+// Warning explains why a neighbour or currency is missing from an exchange response.
+type Warning struct {
+	Neighbour string `json:"neighbour,omitempty"`
+	Currency  string `json:"currency,omitempty"`
+	Reason    string `json:"reason"`
+}
+
+// resolveNeighbours resolves the bordering countries, at most s.concurrency
+// lookups at a time, and returns a warning for each that could not be resolved.
+func (s *service) resolveNeighbours(ctx context.Context, codes []string) ([]borders.Neighbour, []Warning) {
+	neighbours, unresolved := borders.Resolve(ctx, s.countries, codes, s.concurrency)
+	var warnings []Warning
+	for _, u := range unresolved {
+		warnings = append(warnings, Warning{Neighbour: u.Code, Reason: u.Reason})
+	}
+	return neighbours, warnings
+}
+
+// nearbyNeighbours returns the countries within radiusKm of country that do
+// not already share a land border with it. Countries without a currency, such
+// as Antarctica, have nothing to quote and are left out. If the country list cannot be
+// loaded it returns a warning instead, so land neighbours are still quoted.
+func (s *service) nearbyNeighbours(ctx context.Context, country restclient.Country, radiusKm float64) ([]borders.NearbyCountry, []Warning) {
+	graph, err := s.graph.Graph(ctx)
+	if err != nil {
+		slog.WarnContext(ctx, "failed to load countries for proximity neighbours", "error", err)
+		return nil, []Warning{{Reason: "proximity neighbours unavailable: " + borders.FailureReason(err)}}
+	}
+
+	var nearby []borders.NearbyCountry
+	for _, n := range graph.Nearby(country.CCA3, radiusKm) {
+		if len(n.Country.Currencies) == 0 {
+			continue
+		}
+		if !slices.ContainsFunc(country.Borders, func(code string) bool { return strings.EqualFold(code, n.Code) }) {
+			nearby = append(nearby, n)
+		}
+	}
+	return nearby, nil
+}
@@ -0,0 +1,84 @@
+package exchange
+
+import (
+	"countryinfo/internal/util"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// targetOptions are the query parameters that choose which currencies an
+// exchange response quotes and whether it converts an amount.
+type targetOptions struct {
+	// currencies are the ISO 4217 codes requested with ?to=.
+	currencies []string
+	// includeNeighbours is true unless ?to= is given without ?neighbours=true.
+	includeNeighbours bool
+	// proximity adds the countries within radiusKm as neighbours, requested
+	// with ?neighbours=proximity.
+	proximity bool
+	radiusKm  float64
+	// amount is the sum to convert, if ?amount= is given.
+	amount *float64
+}
+
+const (
+	// proximityNeighbours is the ?neighbours= value that adds nearby
+	// countries to the land neighbours.
+	proximityNeighbours = "proximity"
+	defaultRadiusKm     = 1000
+	// maxRadiusKm is about half the Earth's circumference.
+	maxRadiusKm = 20000
+)
+
+// parseTargetOptions reads ?to=, ?neighbours=, ?radius_km= and ?amount=. The
+// ?to= list replaces the neighbours' currencies unless ?neighbours=true or
+// ?neighbours=proximity is also given.
+func parseTargetOptions(query url.Values) (targetOptions, error) {
+	opts := targetOptions{includeNeighbours: true}
+
+	if raw := strings.TrimSpace(query.Get("to")); raw != "" {
+		for _, code := range strings.Split(raw, ",") {
+			code = strings.ToUpper(strings.TrimSpace(code))
+			if !util.IsCurrencyCode(code) {
+				return opts, fmt.Errorf("invalid target currency %q: must be an ISO 4217 code", code)
+			}
+			opts.currencies = append(opts.currencies, code)
+		}
+		opts.includeNeighbours = false
+	}
+
+	if raw := strings.TrimSpace(query.Get("neighbours")); strings.EqualFold(raw, proximityNeighbours) {
+		opts.includeNeighbours = true
+		opts.proximity = true
+		opts.radiusKm = defaultRadiusKm
+	} else if raw != "" {
+		include, err := strconv.ParseBool(raw)
+		if err != nil {
+			return opts, fmt.Errorf("invalid neighbours value %q: must be true, false or proximity", raw)
+		}
+		opts.includeNeighbours = include
+	}
+
+	if raw := strings.TrimSpace(query.Get("radius_km")); raw != "" {
+		if !opts.proximity {
+			return opts, fmt.Errorf("radius_km requires neighbours=proximity")
+		}
+		radius, err := strconv.ParseFloat(raw, 64)
+		if err != nil || !(radius > 0 && radius <= maxRadiusKm) {
+			return opts, fmt.Errorf("invalid radius_km %q: must be a number of kilometres above 0 and at most %d", raw, maxRadiusKm)
+		}
+		opts.radiusKm = radius
+	}
+
+	if raw := strings.TrimSpace(query.Get("amount")); raw != "" {
+		amount, err := util.ParseAmount(raw)
+		if err != nil {
+			return opts, err
+		}
+		opts.amount = &amount
+	}
+
+	return opts, nil
+}
@@ -0,0 +1,133 @@
+package exchange
+
+import (
+	"countryinfo/internal/borders"
+	"countryinfo/internal/resolver"
+	"countryinfo/internal/restclient"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// ExchangeResponseV2 lists every neighbour with its currencies and the rate
+// from the base currency to each, so shared currencies such as EUR are
+// attributed to every neighbour using them.
+type ExchangeResponseV2 struct {
+	Country      string           `json:"country"`
+	BaseCurrency string           `json:"base-currency"`
+	Neighbours   []NeighbourRates `json:"neighbours"`
+	RatesSource  *RatesSource     `json:"rates-source,omitempty"`
+	Warnings     []Warning        `json:"warnings,omitempty"`
+}
+
+// NeighbourRates is one bordering country and the rates to its currencies.
+type NeighbourRates struct {
+	Alpha3     string         `json:"alpha3"`
+	Name       string         `json:"name"`
+	Currencies []CurrencyRate `json:"currencies"`
+}
+
+// CurrencyRate is the rate from the base currency to one currency. Rate is
+// null when the rate table has no entry for the currency.
+type CurrencyRate struct {
+	Code string   `json:"code"`
+	Name string   `json:"name"`
+	Rate *float64 `json:"rate"`
+}
+
+// HandlerV2 serves the v2 exchange response shape.
+func HandlerV2(countries *restclient.CountriesClient, currencies *restclient.CurrencyClient, countryResolver *resolver.Resolver, borderGraph *borders.Loader, opts ...Option) http.HandlerFunc {
+	return newService(countries, currencies, countryResolver, borderGraph, opts).exchangeV2Handler
+}
+
+func (s *service) exchangeV2Handler(w http.ResponseWriter, r *http.Request) {
+	country, bases, ok := s.lookupCountry(w, r)
+	if !ok {
+		return
+	}
+	if bases.all {
+		http.Error(
+			w,
+			fmt.Sprintf("%s\nbase=%s is not supported by v2; request each base currency separately", http.StatusText(http.StatusBadRequest), allBases),
+			http.StatusBadRequest,
+		)
+		return
+	}
+	ctx := r.Context()
+	base := bases.codes[0]
+
+	resp := ExchangeResponseV2{
+		Country:      country.Name.Common,
+		BaseCurrency: base,
+		Neighbours:   []NeighbourRates{},
+	}
+	if len(country.Borders) == 0 {
+		writeJSON(w, r, resp)
+		return
+	}
+
+	neighbours, warnings := s.resolveNeighbours(ctx, country.Borders)
+
+	rates, err := s.currencies.GetExchangeRates(ctx, base)
+	if errors.Is(err, restclient.ErrNotFound) {
+		http.Error(w, fmt.Sprintf("no exchange rates for currency %s", base), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to fetch exchange rates", "error", err, "base_currency", base)
+		http.Error(w, restclient.ErrorMessage(err), restclient.HTTPStatus(err))
+		return
+	}
+	resp.RatesSource = newRatesSource(rates)
+
+	for _, n := range neighbours {
+		alpha3 := n.Country.CCA3
+		if alpha3 == "" {
+			alpha3 = n.Code
+		}
+		entry := NeighbourRates{
+			Alpha3:     strings.ToUpper(alpha3),
+			Name:       n.Country.Name.Common,
+			Currencies: []CurrencyRate{},
+		}
+		if len(n.Country.Currencies) == 0 {
+			warnings = append(warnings, Warning{Neighbour: n.Code, Reason: "neighbour has no currency"})
+		}
+
+		codes := make([]string, 0, len(n.Country.Currencies))
+		for code := range n.Country.Currencies {
+			codes = append(codes, code)
+		}
+		slices.Sort(codes)
+		for _, code := range codes {
+			currency := CurrencyRate{Code: code, Name: n.Country.Currencies[code].Name}
+			if rate, ok := rates.Rates[code]; ok {
+				currency.Rate = &rate
+			} else {
+				warnings = append(warnings, Warning{
+					Neighbour: n.Code,
+					Currency:  code,
+					Reason:    fmt.Sprintf("no exchange rate from %s", base),
+				})
+			}
+			entry.Currencies = append(entry.Currencies, currency)
+		}
+		resp.Neighbours = append(resp.Neighbours, entry)
+	}
+	resp.Warnings = warnings
+
+	if len(warnings) > 0 {
+		w.Header().Set(PartialResultHeader, "true")
+	}
+	writeJSON(w, r, resp)
+
+	slog.InfoContext(ctx, "exchange v2 request completed",
+		"country_code", country.CCA2,
+		"base_currency", base,
+		"neighbours", len(resp.Neighbours),
+		"warnings", len(warnings),
+	)
+}
@@ -0,0 +1,102 @@
+package exchange
+
+import (
+	"countryinfo/internal/restclient"
+	"countryinfo/internal/restclient/restclienttest"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestExchangeV2AttributesRatesToEachNeighbour(t *testing.T) {
+	t.Parallel()
+
+	countriesAPI := restclienttest.NewCountriesAPI(t, []string{
+		`{"cca2":"AT","cca3":"AUT","name":{"common":"Austria"},"currencies":{"EUR":{"name":"Euro"}},"borders":["DEU","CHE","ITA"]}`,
+		`{"cca2":"DE","cca3":"DEU","name":{"common":"Germany"},"currencies":{"EUR":{"name":"Euro"}}}`,
+		`{"cca2":"CH","cca3":"CHE","name":{"common":"Switzerland"},"currencies":{"CHF":{"name":"Swiss franc"}}}`,
+		`{"cca2":"IT","cca3":"ITA","name":{"common":"Italy"},"currencies":{"EUR":{"name":"Euro"}}}`,
+	})
+	defer countriesAPI.Close()
+
+	currencyAPI := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"base_code":"EUR","rates":{"EUR":1,"CHF":0.94}}`))
+	}))
+	defer currencyAPI.Close()
+
+	handler := newHandlerV2(
+		restclient.NewCountriesClient(countriesAPI.URL+"/v3.1"),
+		restclient.NewCurrencyClient(currencyAPI.URL),
+	)
+
+	req := httptest.NewRequest(http.MethodGet, "/countryinfo/v2/exchange/at", nil)
+	req.SetPathValue("country_code", "at")
+	w := httptest.NewRecorder()
+
+	handler(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d; body: %s", w.Code, w.Body.String())
+	}
+
+	var resp ExchangeResponseV2
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+	if resp.BaseCurrency != "EUR" {
+		t.Errorf("expected base currency EUR, got %q", resp.BaseCurrency)
+	}
+
+	want := []struct {
+		alpha3, name, currency string
+		rate                   float64
+	}{
+		{"DEU", "Germany", "EUR", 1},
+		{"CHE", "Switzerland", "CHF", 0.94},
+		{"ITA", "Italy", "EUR", 1},
+	}
+	if len(resp.Neighbours) != len(want) {
+		t.Fatalf("expected %d neighbours, got %+v", len(want), resp.Neighbours)
+	}
+	for i, n := range resp.Neighbours {
+		if n.Alpha3 != want[i].alpha3 || n.Name != want[i].name {
+			t.Errorf("neighbour %d = %s (%s), want %s (%s)", i, n.Alpha3, n.Name, want[i].alpha3, want[i].name)
+		}
+		if len(n.Currencies) != 1 || n.Currencies[0].Code != want[i].currency {
+			t.Fatalf("neighbour %s currencies = %+v, want %s", n.Alpha3, n.Currencies, want[i].currency)
+		}
+		if rate := n.Currencies[0].Rate; rate == nil || *rate != want[i].rate {
+			t.Errorf("neighbour %s rate = %v, want %v", n.Alpha3, rate, want[i].rate)
+		}
+	}
+}
+
+func TestExchangeV2RejectsAllBases(t *testing.T) {
+	t.Parallel()
+
+	countriesAPI := restclienttest.NewCountriesAPI(t, []string{
+		`{"cca2":"PA","cca3":"PAN","name":{"common":"Panama"},"currencies":{"USD":{},"PAB":{}}}`,
+		`{"cca2":"NO","cca3":"NOR","name":{"common":"Norway"},"currencies":{"NOK":{}}}`,
+	})
+	defer countriesAPI.Close()
+
+	handler := newHandlerV2(
+		restclient.NewCountriesClient(countriesAPI.URL+"/v3.1"),
+		restclient.NewCurrencyClient("http://example.com"),
+	)
+
+	// Norway uses a single currency, but base=all is rejected all the same.
+	for _, code := range []string{"pa", "no"} {
+		req := httptest.NewRequest(http.MethodGet, "/countryinfo/v2/exchange/"+code+"?base=all", nil)
+		req.SetPathValue("country_code", code)
+		w := httptest.NewRecorder()
+
+		handler(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected status 400, got %d", code, w.Code)
+		}
+	}
+}
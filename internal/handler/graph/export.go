@@ -0,0 +1,213 @@
+package graph
+
+import (
+	"bytes"
+	"countryinfo/internal/borders"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+)
+
+// Export formats accepted by ?format=.
+const (
+	FormatDOT     = "dot"
+	FormatGraphML = "graphml"
+	FormatGeoJSON = "geojson"
+)
+
+var contentTypes = map[string]string{
+	FormatDOT:     "text/vnd.graphviz; charset=utf-8",
+	FormatGraphML: "application/graphml+xml; charset=utf-8",
+	FormatGeoJSON: "application/geo+json",
+}
+
+// Handler serves the full border graph in the format chosen with ?format=,
+// GeoJSON by default.
+func Handler(borderGraph *borders.Loader) http.HandlerFunc {
+	return newService(borderGraph).exportHandler
+}
+
+func (s *service) exportHandler(w http.ResponseWriter, r *http.Request) {
+	format := strings.ToLower(strings.TrimSpace(r.URL.Query().Get("format")))
+	if format == "" {
+		format = FormatGeoJSON
+	}
+	contentType, ok := contentTypes[format]
+	if !ok {
+		http.Error(
+			w,
+			fmt.Sprintf("%s\nformat must be one of dot, graphml, geojson", http.StatusText(http.StatusBadRequest)),
+			http.StatusBadRequest,
+		)
+		return
+	}
+
+	g, ok := s.load(w, r)
+	if !ok {
+		return
+	}
+
+	var data []byte
+	var err error
+	switch format {
+	case FormatDOT:
+		data = encodeDOT(g)
+	case FormatGraphML:
+		data, err = encodeGraphML(g)
+	case FormatGeoJSON:
+		data, err = encodeGeoJSON(g)
+	}
+	if err != nil {
+		slog.ErrorContext(r.Context(), "failed to encode border graph", "error", err, "format", format)
+		http.Error(w, "failed to encode border graph", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", contentType)
+	_, _ = w.Write(data)
+
+	slog.InfoContext(r.Context(), "graph export completed", "format", format)
+}
+
+// encodeDOT writes the graph in the Graphviz DOT language, one node statement
+// per country followed by one edge statement per border.
+func encodeDOT(g *borders.Graph) []byte {
+	var b bytes.Buffer
+	b.WriteString("graph borders {\n")
+	for _, code := range g.Codes() {
+		c, _ := g.Country(code)
+		fmt.Fprintf(&b, "  %q [label=%q, region=%q, population=%d];\n", code, c.Name.Common, c.Region, c.Population)
+	}
+	for _, e := range g.Edges() {
+		fmt.Fprintf(&b, "  %q -- %q;\n", e[0], e[1])
+	}
+	b.WriteString("}\n")
+	return b.Bytes()
+}
+
+type graphML struct {
+	XMLName xml.Name     `xml:"graphml"`
+	XMLNS   string       `xml:"xmlns,attr"`
+	Keys    []graphMLKey `xml:"key"`
+	Graph   struct {
+		ID          string        `xml:"id,attr"`
+		EdgeDefault string        `xml:"edgedefault,attr"`
+		Nodes       []graphMLNode `xml:"node"`
+		Edges       []graphMLEdge `xml:"edge"`
+	} `xml:"graph"`
+}
+
+type graphMLKey struct {
+	ID   string `xml:"id,attr"`
+	For  string `xml:"for,attr"`
+	Name string `xml:"attr.name,attr"`
+	Type string `xml:"attr.type,attr"`
+}
+
+type graphMLNode struct {
+	ID   string        `xml:"id,attr"`
+	Data []graphMLData `xml:"data"`
+}
+
+type graphMLData struct {
+	Key   string `xml:"key,attr"`
+	Value string `xml:",chardata"`
+}
+
+type graphMLEdge struct {
+	Source string `xml:"source,attr"`
+	Target string `xml:"target,attr"`
+}
+
+// encodeGraphML writes the graph as an undirected GraphML document with the
+// name, region and population of each country as node data.
+func encodeGraphML(g *borders.Graph) ([]byte, error) {
+	doc := graphML{
+		XMLNS: "http://graphml.graphdrawing.org/xmlns",
+		Keys: []graphMLKey{
+			{ID: "name", For: "node", Name: "name", Type: "string"},
+			{ID: "region", For: "node", Name: "region", Type: "string"},
+			{ID: "population", For: "node", Name: "population", Type: "long"},
+		},
+	}
+	doc.Graph.ID = "borders"
+	doc.Graph.EdgeDefault = "undirected"
+	for _, code := range g.Codes() {
+		c, _ := g.Country(code)
+		doc.Graph.Nodes = append(doc.Graph.Nodes, graphMLNode{ID: code, Data: []graphMLData{
+			{Key: "name", Value: c.Name.Common},
+			{Key: "region", Value: c.Region},
+			{Key: "population", Value: fmt.Sprint(c.Population)},
+		}})
+	}
+	for _, e := range g.Edges() {
+		doc.Graph.Edges = append(doc.Graph.Edges, graphMLEdge{Source: e[0], Target: e[1]})
+	}
+
+	data, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), append(data, '\n')...), nil
+}
+
+type featureCollection struct {
+	Type     string    `json:"type"`
+	Features []feature `json:"features"`
+}
+
+type feature struct {
+	Type       string         `json:"type"`
+	Geometry   geometry       `json:"geometry"`
+	Properties map[string]any `json:"properties"`
+}
+
+type geometry struct {
+	Type        string `json:"type"`
+	Coordinates any    `json:"coordinates"`
+}
+
+// encodeGeoJSON writes the graph as a GeoJSON FeatureCollection: a Point at
+// each country's latlng followed by a LineString per border. Countries
+// without coordinates, and their borders, are left out.
+func encodeGeoJSON(g *borders.Graph) ([]byte, error) {
+	positions := make(map[string][2]float64)
+	collection := featureCollection{Type: "FeatureCollection", Features: []feature{}}
+	for _, code := range g.Codes() {
+		c, _ := g.Country(code)
+		if len(c.LatLng) < 2 {
+			continue
+		}
+		// GeoJSON positions are longitude first.
+		positions[code] = [2]float64{c.LatLng[1], c.LatLng[0]}
+		collection.Features = append(collection.Features, feature{
+			Type:     "Feature",
+			Geometry: geometry{Type: "Point", Coordinates: positions[code]},
+			Properties: map[string]any{
+				"alpha2":     c.CCA2,
+				"alpha3":     code,
+				"name":       c.Name.Common,
+				"region":     c.Region,
+				"population": c.Population,
+			},
+		})
+	}
+	for _, e := range g.Edges() {
+		from, ok := positions[e[0]]
+		if !ok {
+			continue
+		}
+		to, ok := positions[e[1]]
+		if !ok {
+			continue
+		}
+		collection.Features = append(collection.Features, feature{
+			Type:       "Feature",
+			Geometry:   geometry{Type: "LineString", Coordinates: [][2]float64{from, to}},
+			Properties: map[string]any{"from": e[0], "to": e[1]},
+		})
+	}
+	return json.Marshal(collection)
+}
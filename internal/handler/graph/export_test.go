@@ -0,0 +1,107 @@
+package graph
+
+import (
+	"countryinfo/internal/borders"
+	"countryinfo/internal/restclient"
+	"countryinfo/internal/restclient/restclienttest"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+var exportCountries = []string{
+	`{"cca2":"NO","cca3":"NOR","name":{"common":"Norway"},"region":"Europe","population":5379475,"latlng":[62,10],"borders":["SWE"]}`,
+	`{"cca2":"SE","cca3":"SWE","name":{"common":"Sweden"},"region":"Europe","population":10353442,"latlng":[62,15],"borders":["NOR"]}`,
+	`{"cca2":"AQ","cca3":"ATA","name":{"common":"Antarctica"},"region":"Antarctic","population":1000}`,
+}
+
+func serveExport(t *testing.T, query string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	countriesAPI := restclienttest.NewCountriesAPI(t, exportCountries)
+	defer countriesAPI.Close()
+
+	handler := Handler(borders.NewLoader(restclient.NewCountriesClient(countriesAPI.URL + "/v3.1")))
+	req := httptest.NewRequest(http.MethodGet, "/countryinfo/v1/graph"+query, nil)
+	w := httptest.NewRecorder()
+	handler(w, req)
+	return w
+}
+
+func TestExportHandlerDOT(t *testing.T) {
+	t.Parallel()
+
+	w := serveExport(t, "?format=dot")
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", w.Code)
+	}
+	if got := w.Header().Get("Content-Type"); !strings.HasPrefix(got, "text/vnd.graphviz") {
+		t.Errorf("unexpected content type %q", got)
+	}
+	expected := "graph borders {\n" +
+		`  "ATA" [label="Antarctica", region="Antarctic", population=1000];` + "\n" +
+		`  "NOR" [label="Norway", region="Europe", population=5379475];` + "\n" +
+		`  "SWE" [label="Sweden", region="Europe", population=10353442];` + "\n" +
+		`  "NOR" -- "SWE";` + "\n" +
+		"}\n"
+	if got := w.Body.String(); got != expected {
+		t.Fatalf("unexpected response body:\ngot:  %s\nwant: %s", got, expected)
+	}
+}
+
+func TestExportHandlerGraphML(t *testing.T) {
+	t.Parallel()
+
+	w := serveExport(t, "?format=graphml")
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", w.Code)
+	}
+	body := w.Body.String()
+	for _, want := range []string{
+		`<graph id="borders" edgedefault="undirected">`,
+		`<node id="NOR">`,
+		`<data key="population">5379475</data>`,
+		`<edge source="NOR" target="SWE"></edge>`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("expected body to contain %q, got:\n%s", want, body)
+		}
+	}
+}
+
+func TestExportHandlerGeoJSON(t *testing.T) {
+	t.Parallel()
+
+	w := serveExport(t, "")
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", w.Code)
+	}
+	if got := w.Header().Get("Content-Type"); got != "application/geo+json" {
+		t.Errorf("unexpected content type %q", got)
+	}
+	expected := `{"type":"FeatureCollection","features":[` +
+		`{"type":"Feature","geometry":{"type":"Point","coordinates":[10,62]},"properties":{"alpha2":"NO","alpha3":"NOR","name":"Norway","population":5379475,"region":"Europe"}},` +
+		`{"type":"Feature","geometry":{"type":"Point","coordinates":[15,62]},"properties":{"alpha2":"SE","alpha3":"SWE","name":"Sweden","population":10353442,"region":"Europe"}},` +
+		`{"type":"Feature","geometry":{"type":"LineString","coordinates":[[10,62],[15,62]]},"properties":{"from":"NOR","to":"SWE"}}]}`
+	if got := w.Body.String(); got != expected {
+		t.Fatalf("unexpected response body:\ngot:  %s\nwant: %s", got, expected)
+	}
+}
+
+func TestExportHandlerRejectsUnknownFormat(t *testing.T) {
+	t.Parallel()
+
+	handler := Handler(borders.NewLoader(restclient.NewCountriesClient("http://example.com")))
+	req := httptest.NewRequest(http.MethodGet, "/countryinfo/v1/graph?format=svg", nil)
+	w := httptest.NewRecorder()
+
+	handler(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected status 400, got %d", w.Code)
+	}
+}
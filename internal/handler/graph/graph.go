@@ -0,0 +1,245 @@
+package graph
+
+import (
+	"countryinfo/internal/borders"
+	"countryinfo/internal/restclient"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Country identifies a country in a graph analytics response.
+type Country struct {
+	Name   string `json:"name"`
+	Alpha2 string `json:"alpha2"`
+	Alpha3 string `json:"alpha3"`
+}
+
+// LandmassesResponse lists the connected components of the border graph.
+type LandmassesResponse struct {
+	Count      int        `json:"count"`
+	Landmasses []Landmass `json:"landmasses"`
+}
+
+// Landmass is a set of countries connected by land borders.
+type Landmass struct {
+	Size      int       `json:"size"`
+	Countries []Country `json:"countries"`
+}
+
+// EnclavesResponse lists the enclaves and doubly landlocked countries.
+type EnclavesResponse struct {
+	Enclaves         []Enclave `json:"enclaves"`
+	DoubleLandlocked []Country `json:"double-landlocked"`
+}
+
+// Enclave is a country entirely surrounded by another.
+type Enclave struct {
+	Country
+	SurroundedBy Country `json:"surrounded-by"`
+}
+
+// RankingsResponse lists countries by number of neighbours.
+type RankingsResponse struct {
+	Count     int       `json:"count"`
+	Countries []Ranking `json:"countries"`
+}
+
+// Ranking is a country's number of neighbours and its rank by that number.
+// Countries with as many neighbours share a rank.
+type Ranking struct {
+	Rank int `json:"rank"`
+	Country
+	Neighbours int `json:"neighbours"`
+}
+
+// AsymmetriesResponse lists the border entries the upstream data does not
+// agree on.
+type AsymmetriesResponse struct {
+	Count       int         `json:"count"`
+	Asymmetries []BorderRef `json:"asymmetries"`
+	// Unknown lists border entries naming no known country.
+	Unknown []BorderRef `json:"unknown"`
+}
+
+// BorderRef is a border entry: Country lists Border among its borders.
+type BorderRef struct {
+	Country string `json:"country"`
+	Border  string `json:"border"`
+}
+
+const (
+	defaultLimit = 20
+	maxLimit     = 250
+)
+
+type service struct {
+	graph *borders.Loader
+}
+
+func newService(borderGraph *borders.Loader) *service {
+	return &service{
+		graph: borderGraph,
+	}
+}
+
+// LandmassesHandler serves the landmasses of the border graph, largest first.
+func LandmassesHandler(borderGraph *borders.Loader) http.HandlerFunc {
+	return newService(borderGraph).landmassesHandler
+}
+
+// EnclavesHandler serves the enclaves and doubly landlocked countries.
+func EnclavesHandler(borderGraph *borders.Loader) http.HandlerFunc {
+	return newService(borderGraph).enclavesHandler
+}
+
+// RankingsHandler serves the countries with the most neighbours.
+func RankingsHandler(borderGraph *borders.Loader) http.HandlerFunc {
+	return newService(borderGraph).rankingsHandler
+}
+
+// AsymmetriesHandler serves the border entries listed by only one side, as a
+// data-quality check on the upstream.
+func AsymmetriesHandler(borderGraph *borders.Loader) http.HandlerFunc {
+	return newService(borderGraph).asymmetriesHandler
+}
+
+func (s *service) landmassesHandler(w http.ResponseWriter, r *http.Request) {
+	g, ok := s.load(w, r)
+	if !ok {
+		return
+	}
+
+	resp := LandmassesResponse{Landmasses: []Landmass{}}
+	for _, codes := range g.Components() {
+		resp.Landmasses = append(resp.Landmasses, Landmass{Size: len(codes), Countries: newCountries(g, codes)})
+	}
+	resp.Count = len(resp.Landmasses)
+	writeJSON(w, r, resp)
+
+	slog.InfoContext(r.Context(), "landmasses request completed", "landmasses", resp.Count)
+}
+
+func (s *service) enclavesHandler(w http.ResponseWriter, r *http.Request) {
+	g, ok := s.load(w, r)
+	if !ok {
+		return
+	}
+
+	resp := EnclavesResponse{
+		Enclaves:         []Enclave{},
+		DoubleLandlocked: newCountries(g, g.DoublyLandlocked()),
+	}
+	for _, code := range g.Enclaves() {
+		resp.Enclaves = append(resp.Enclaves, Enclave{
+			Country:      newCountry(g, code),
+			SurroundedBy: newCountry(g, g.Neighbours(code)[0]),
+		})
+	}
+	writeJSON(w, r, resp)
+
+	slog.InfoContext(r.Context(), "enclaves request completed",
+		"enclaves", len(resp.Enclaves),
+		"double_landlocked", len(resp.DoubleLandlocked),
+	)
+}
+
+func (s *service) rankingsHandler(w http.ResponseWriter, r *http.Request) {
+	limit := defaultLimit
+	if raw := strings.TrimSpace(r.URL.Query().Get("limit")); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 1 || parsed > maxLimit {
+			http.Error(
+				w,
+				fmt.Sprintf("%s\nlimit must be an integer between 1 and %d", http.StatusText(http.StatusBadRequest), maxLimit),
+				http.StatusBadRequest,
+			)
+			return
+		}
+		limit = parsed
+	}
+
+	g, ok := s.load(w, r)
+	if !ok {
+		return
+	}
+
+	resp := RankingsResponse{Countries: []Ranking{}}
+	for i, code := range g.Ranking() {
+		if i == limit {
+			break
+		}
+		ranking := Ranking{Rank: i + 1, Country: newCountry(g, code), Neighbours: len(g.Neighbours(code))}
+		if i > 0 && resp.Countries[i-1].Neighbours == ranking.Neighbours {
+			ranking.Rank = resp.Countries[i-1].Rank
+		}
+		resp.Countries = append(resp.Countries, ranking)
+	}
+	resp.Count = len(resp.Countries)
+	writeJSON(w, r, resp)
+
+	slog.InfoContext(r.Context(), "rankings request completed", "limit", limit)
+}
+
+func (s *service) asymmetriesHandler(w http.ResponseWriter, r *http.Request) {
+	g, ok := s.load(w, r)
+	if !ok {
+		return
+	}
+
+	resp := AsymmetriesResponse{Asymmetries: []BorderRef{}, Unknown: []BorderRef{}}
+	for _, a := range g.Asymmetries() {
+		resp.Asymmetries = append(resp.Asymmetries, BorderRef{Country: a.Country, Border: a.Border})
+	}
+	for _, code := range g.Codes() {
+		for _, border := range g.Missing(code) {
+			resp.Unknown = append(resp.Unknown, BorderRef{Country: code, Border: border})
+		}
+	}
+	resp.Count = len(resp.Asymmetries)
+	writeJSON(w, r, resp)
+
+	slog.InfoContext(r.Context(), "asymmetries request completed",
+		"asymmetries", resp.Count,
+		"unknown", len(resp.Unknown),
+	)
+}
+
+// load returns the border graph, writing the upstream error response and
+// returning false if it cannot be built.
+func (s *service) load(w http.ResponseWriter, r *http.Request) (*borders.Graph, bool) {
+	g, err := s.graph.Graph(r.Context())
+	if err != nil {
+		slog.ErrorContext(r.Context(), "failed to load border graph", "error", err)
+		http.Error(w, restclient.ErrorMessage(err), restclient.HTTPStatus(err))
+		return nil, false
+	}
+	return g, true
+}
+
+func newCountry(g *borders.Graph, code string) Country {
+	c, _ := g.Country(code)
+	return Country{Name: c.Name.Common, Alpha2: c.CCA2, Alpha3: code}
+}
+
+func newCountries(g *borders.Graph, codes []string) []Country {
+	countries := make([]Country, 0, len(codes))
+	for _, code := range codes {
+		countries = append(countries, newCountry(g, code))
+	}
+	return countries
+}
+
+func writeJSON(w http.ResponseWriter, r *http.Request, v any) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "failed to marshal json", "error", err)
+		http.Error(w, "failed to marshal json", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(data)
+}
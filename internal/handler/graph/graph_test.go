@@ -0,0 +1,93 @@
+package graph
+
+import (
+	"countryinfo/internal/borders"
+	"countryinfo/internal/restclient"
+	"countryinfo/internal/restclient/restclienttest"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+var testCountries = []string{
+	`{"cca2":"ZA","cca3":"ZAF","name":{"common":"South Africa"},"borders":["LSO"]}`,
+	`{"cca2":"LS","cca3":"LSO","name":{"common":"Lesotho"},"landlocked":true,"borders":["ZAF"]}`,
+	`{"cca2":"CH","cca3":"CHE","name":{"common":"Switzerland"},"landlocked":true,"borders":["AUT","LIE"]}`,
+	`{"cca2":"AT","cca3":"AUT","name":{"common":"Austria"},"landlocked":true,"borders":["CHE","DEU","LIE"]}`,
+	`{"cca2":"LI","cca3":"LIE","name":{"common":"Liechtenstein"},"landlocked":true,"borders":["AUT","CHE"]}`,
+	`{"cca2":"DE","cca3":"DEU","name":{"common":"Germany"},"borders":["AUT","CHE","XXX"]}`,
+	`{"cca2":"IS","cca3":"ISL","name":{"common":"Iceland"}}`,
+}
+
+func TestGraphAnalyticsHandlers(t *testing.T) {
+	t.Parallel()
+
+	countriesAPI := restclienttest.NewCountriesAPI(t, testCountries)
+	defer countriesAPI.Close()
+	borderGraph := borders.NewLoader(restclient.NewCountriesClient(countriesAPI.URL + "/v3.1"))
+
+	tests := []struct {
+		name     string
+		handler  http.HandlerFunc
+		query    string
+		expected string
+	}{
+		{
+			name:    "landmasses",
+			handler: LandmassesHandler(borderGraph),
+			expected: `{"count":3,"landmasses":[` +
+				`{"size":4,"countries":[{"name":"Austria","alpha2":"AT","alpha3":"AUT"},{"name":"Switzerland","alpha2":"CH","alpha3":"CHE"},{"name":"Germany","alpha2":"DE","alpha3":"DEU"},{"name":"Liechtenstein","alpha2":"LI","alpha3":"LIE"}]},` +
+				`{"size":2,"countries":[{"name":"Lesotho","alpha2":"LS","alpha3":"LSO"},{"name":"South Africa","alpha2":"ZA","alpha3":"ZAF"}]},` +
+				`{"size":1,"countries":[{"name":"Iceland","alpha2":"IS","alpha3":"ISL"}]}]}`,
+		},
+		{
+			name:    "enclaves",
+			handler: EnclavesHandler(borderGraph),
+			expected: `{"enclaves":[{"name":"Lesotho","alpha2":"LS","alpha3":"LSO","surrounded-by":{"name":"South Africa","alpha2":"ZA","alpha3":"ZAF"}}],` +
+				`"double-landlocked":[{"name":"Liechtenstein","alpha2":"LI","alpha3":"LIE"}]}`,
+		},
+		{
+			name:    "rankings",
+			handler: RankingsHandler(borderGraph),
+			query:   "?limit=3",
+			expected: `{"count":3,"countries":[` +
+				`{"rank":1,"name":"Austria","alpha2":"AT","alpha3":"AUT","neighbours":3},` +
+				`{"rank":1,"name":"Switzerland","alpha2":"CH","alpha3":"CHE","neighbours":3},` +
+				`{"rank":3,"name":"Germany","alpha2":"DE","alpha3":"DEU","neighbours":2}]}`,
+		},
+		{
+			name:     "asymmetries",
+			handler:  AsymmetriesHandler(borderGraph),
+			expected: `{"count":1,"asymmetries":[{"country":"DEU","border":"CHE"}],"unknown":[{"country":"DEU","border":"XXX"}]}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/countryinfo/v1/graph/"+tt.name+tt.query, nil)
+			w := httptest.NewRecorder()
+
+			tt.handler(w, req)
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("expected status 200, got %d", w.Code)
+			}
+			if got := w.Body.String(); got != tt.expected {
+				t.Fatalf("unexpected response body:\ngot:  %s\nwant: %s", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestRankingsHandlerRejectsInvalidLimit(t *testing.T) {
+	t.Parallel()
+
+	handler := RankingsHandler(borders.NewLoader(restclient.NewCountriesClient("http://example.com")))
+	req := httptest.NewRequest(http.MethodGet, "/countryinfo/v1/graph/rankings?limit=0", nil)
+	w := httptest.NewRecorder()
+
+	handler(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected status 400, got %d", w.Code)
+	}
+}
@@ -0,0 +1,81 @@
+package info
+
+import "countryinfo/internal/restclient"
+
+// Views of a country selected with ?view=.
+const (
+	viewBasic = "basic"
+	viewFull  = "full"
+)
+
+// DetailedResponse is the ?view=full info response: the basic fields plus the
+// rest of the upstream country record.
+type DetailedResponse struct {
+	Response
+	OfficialName  string                            `json:"official-name"`
+	Alpha2        string                            `json:"alpha2"`
+	Alpha3        string                            `json:"alpha3"`
+	Numeric       string                            `json:"numeric"`
+	CIOC          string                            `json:"cioc,omitempty"`
+	TLD           []string                          `json:"tld"`
+	CallingCodes  []string                          `json:"calling-codes"`
+	Currencies    map[string]restclient.Currency    `json:"currencies"`
+	Region        string                            `json:"region"`
+	Subregion     string                            `json:"subregion"`
+	LatLng        []float64                         `json:"latlng"`
+	CapitalLatLng []float64                         `json:"capital-latlng"`
+	Timezones     []string                          `json:"timezones"`
+	Demonyms      map[string]restclient.Demonym     `json:"demonyms"`
+	Translations  map[string]restclient.CountryName `json:"translations"`
+	// Gini maps the survey year to the Gini coefficient.
+	Gini        map[string]float64     `json:"gini,omitempty"`
+	Car         restclient.Car         `json:"car"`
+	PostalCode  *restclient.PostalCode `json:"postal-code,omitempty"`
+	Landlocked  bool                   `json:"landlocked"`
+	Independent bool                   `json:"independent"`
+	UNMember    bool                   `json:"un-member"`
+	StartOfWeek string                 `json:"start-of-week"`
+	Maps        Maps                   `json:"maps"`
+}
+
+// Maps links to the country on online maps.
+type Maps struct {
+	GoogleMaps     string `json:"google-maps"`
+	OpenStreetMaps string `json:"openstreetmap"`
+}
+
+func NewDetailedResponse(c restclient.Country) DetailedResponse {
+	var postalCode *restclient.PostalCode
+	if c.PostalCode.Format != "" || c.PostalCode.Regex != "" {
+		postalCode = &c.PostalCode
+	}
+	return DetailedResponse{
+		Response:      NewResponse(c),
+		OfficialName:  c.Name.Official,
+		Alpha2:        c.CCA2,
+		Alpha3:        c.CCA3,
+		Numeric:       c.CCN3,
+		CIOC:          c.CIOC,
+		TLD:           c.TLD,
+		CallingCodes:  c.IDD.CallingCodes(),
+		Currencies:    c.Currencies,
+		Region:        c.Region,
+		Subregion:     c.Subregion,
+		LatLng:        c.LatLng,
+		CapitalLatLng: c.CapitalInfo.LatLng,
+		Timezones:     c.Timezones,
+		Demonyms:      c.Demonyms,
+		Translations:  c.Translations,
+		Gini:          c.Gini,
+		Car:           c.Car,
+		PostalCode:    postalCode,
+		Landlocked:    c.Landlocked,
+		Independent:   c.Independent,
+		UNMember:      c.UNMember,
+		StartOfWeek:   c.StartOfWeek,
+		Maps: Maps{
+			GoogleMaps:     c.Maps.GoogleMaps,
+			OpenStreetMaps: c.Maps.OpenStreetMaps,
+		},
+	}
+}
@@ -0,0 +1,122 @@
+package info
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// upstreamFields maps every field of DetailedResponse to the upstream
+// REST Countries fields it is built from.
+var upstreamFields = map[string][]string{
+	"name":           {"name"},
+	"continents":     {"continents"},
+	"population":     {"population"},
+	"area":           {"area"},
+	"languages":      {"languages"},
+	"borders":        {"borders"},
+	"flag":           {"flags"},
+	"capital":        {"capital"},
+	"capitals":       {"capital", "capitalInfo"},
+	"official-name":  {"name"},
+	"alpha2":         {"cca2"},
+	"alpha3":         {"cca3"},
+	"numeric":        {"ccn3"},
+	"cioc":           {"cioc"},
+	"tld":            {"tld"},
+	"calling-codes":  {"idd"},
+	"currencies":     {"currencies"},
+	"region":         {"region"},
+	"subregion":      {"subregion"},
+	"latlng":         {"latlng"},
+	"capital-latlng": {"capitalInfo"},
+	"timezones":      {"timezones"},
+	"demonyms":       {"demonyms"},
+	"translations":   {"translations"},
+	"gini":           {"gini"},
+	"car":            {"car"},
+	"postal-code":    {"postalCode"},
+	"landlocked":     {"landlocked"},
+	"independent":    {"independent"},
+	"un-member":      {"unMember"},
+	"start-of-week":  {"startOfWeek"},
+	"maps":           {"maps"},
+}
+
+// parseFields parses a comma-separated ?fields= list, dropping blanks and
+// duplicates. It returns nil if no fields are requested.
+func parseFields(raw string) ([]string, error) {
+	var fields, unknown []string
+	for _, field := range strings.Split(raw, ",") {
+		field = strings.ToLower(strings.TrimSpace(field))
+		if field == "" || slices.Contains(fields, field) {
+			continue
+		}
+		if _, ok := upstreamFields[field]; !ok {
+			unknown = append(unknown, field)
+			continue
+		}
+		fields = append(fields, field)
+	}
+	if len(unknown) > 0 {
+		valid := make([]string, 0, len(upstreamFields))
+		for field := range upstreamFields {
+			valid = append(valid, field)
+		}
+		slices.Sort(valid)
+		return nil, fmt.Errorf("unknown fields: %s; valid fields are: %s",
+			strings.Join(unknown, ", "), strings.Join(valid, ", "))
+	}
+	return fields, nil
+}
+
+// localizedFields are the fields translated when a language is requested.
+var localizedFields = []string{"name", "official-name"}
+
+// upstreamFieldsFor returns the upstream fields needed to build fields,
+// including the translations if names are to be localised. The alpha-2 code
+// is always included, to identify the country in logs.
+func upstreamFieldsFor(fields []string, localized bool) []string {
+	needed := []string{"cca2"}
+	for _, field := range fields {
+		needed = append(needed, upstreamFields[field]...)
+		if localized && slices.Contains(localizedFields, field) {
+			needed = append(needed, "translations")
+		}
+	}
+	slices.Sort(needed)
+	return slices.Compact(needed)
+}
+
+// selectFields marshals v, a response struct, keeping only fields in the
+// requested order. Requested fields omitted from v are written as null.
+func selectFields(v any, fields []string) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var all map[string]json.RawMessage
+	if err := json.Unmarshal(data, &all); err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, field := range fields {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, _ := json.Marshal(field)
+		buf.Write(key)
+		buf.WriteByte(':')
+		if value, ok := all[field]; ok {
+			buf.Write(value)
+		} else {
+			buf.WriteString("null")
+		}
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
@@ -1,8 +1,9 @@
 package info
 
 import (
+	"countryinfo/internal/locale"
+	"countryinfo/internal/resolver"
 	"countryinfo/internal/restclient"
-	"countryinfo/internal/util"
 	"encoding/json"
 	"fmt"
 	"log/slog"
@@ -11,7 +12,7 @@ import (
 )
 
 type service struct {
-	countries *restclient.CountriesClient
+	resolver *resolver.Resolver
 }
 
 type Response struct {
@@ -22,7 +23,29 @@ type Response struct {
 	Languages  map[string]string `json:"languages"`
 	Borders    []string          `json:"borders"`
 	Flag       string            `json:"flag"`
-	Capital    string            `json:"capital"`
+	// Capital is the first of Capitals, kept for compatibility.
+	Capital  string    `json:"capital"`
+	Capitals []Capital `json:"capitals"`
+}
+
+// Capital is a capital city. The upstream only locates a country's first
+// capital, so LatLng is omitted for the others.
+type Capital struct {
+	Name   string    `json:"name"`
+	LatLng []float64 `json:"latlng,omitempty"`
+}
+
+// NewCapitals returns every capital of the country, in upstream order.
+func NewCapitals(c restclient.Country) []Capital {
+	capitals := make([]Capital, 0, len(c.Capital))
+	for i, name := range c.Capital {
+		capital := Capital{Name: name}
+		if i == 0 && len(c.CapitalInfo.LatLng) == 2 {
+			capital.LatLng = c.CapitalInfo.LatLng
+		}
+		capitals = append(capitals, capital)
+	}
+	return capitals
 }
 
 func NewResponse(c restclient.Country) Response {
@@ -39,50 +62,89 @@ func NewResponse(c restclient.Country) Response {
 		Borders:    c.Borders,
 		Flag:       c.Flags.Png,
 		Capital:    capital,
+		Capitals:   NewCapitals(c),
 	}
 }
 
-func Handler(countries *restclient.CountriesClient) http.HandlerFunc {
+func Handler(countryResolver *resolver.Resolver) http.HandlerFunc {
 	s := &service{
-		countries: countries,
+		resolver: countryResolver,
 	}
 	return s.infoHandler
 }
 
 func (s *service) infoHandler(w http.ResponseWriter, r *http.Request) {
-	countryCode := strings.ToLower(strings.TrimSpace(r.PathValue("country_code")))
-	if !util.IsTwoLetterCountryCode(countryCode) {
+	view := strings.ToLower(strings.TrimSpace(r.URL.Query().Get("view")))
+	if view == "" {
+		view = viewBasic
+	}
+	if view != viewBasic && view != viewFull {
 		http.Error(
 			w,
-			fmt.Sprintf("%s\ninvalid country code: %s", http.StatusText(http.StatusBadRequest), countryCode),
+			fmt.Sprintf("%s\ninvalid view: %s (expected %s or %s)", http.StatusText(http.StatusBadRequest), view, viewBasic, viewFull),
 			http.StatusBadRequest,
 		)
 		return
 	}
 
-	countries, err := s.countries.GetByAlpha(r.Context(), countryCode)
+	// A sparse fieldset may name any field of the full view, and only the
+	// upstream fields it needs are requested.
+	fields, err := parseFields(r.URL.Query().Get("fields"))
 	if err != nil {
-		slog.ErrorContext(r.Context(), "upstream countries request failed", "error", err)
-		http.Error(w, "failed to reach countries endpoint", http.StatusBadGateway)
+		http.Error(w, fmt.Sprintf("%s\n%s", http.StatusText(http.StatusBadRequest), err), http.StatusBadRequest)
 		return
 	}
-	if len(countries) == 0 {
-		http.Error(w, "country not found", http.StatusNotFound)
+
+	// Names are localised to the languages of ?lang= or, without it, of the
+	// Accept-Language header.
+	w.Header().Add("Vary", "Accept-Language")
+	prefs := locale.ParsePreferences(r.URL.Query().Get("lang"))
+	if len(prefs) == 0 {
+		prefs = locale.ParsePreferences(r.Header.Get("Accept-Language"))
+	}
+
+	var upstream []string
+	if len(fields) > 0 {
+		upstream = upstreamFieldsFor(fields, len(prefs) > 0)
+	}
+
+	query := r.PathValue("country_code")
+	country, err := s.resolver.Resolve(r.Context(), query, upstream...)
+	if err != nil {
+		resolver.WriteError(w, r, query, err)
 		return
 	}
 
-	resJson, err := json.Marshal(NewResponse(countries[0]))
+	names := locale.Select(country, prefs)
+	var resJson []byte
+	if len(fields) > 0 || view == viewFull {
+		resp := NewDetailedResponse(country)
+		resp.Name, resp.OfficialName = names.Common, names.Official
+		if len(fields) > 0 {
+			resJson, err = selectFields(resp, fields)
+		} else {
+			resJson, err = json.Marshal(resp)
+		}
+	} else {
+		resp := NewResponse(country)
+		resp.Name = names.Common
+		resJson, err = json.Marshal(resp)
+	}
 	if err != nil {
 		http.Error(w, "failed to marshal json", http.StatusInternalServerError)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Language", names.Language)
 	_, _ = w.Write(resJson)
 
 	slog.InfoContext(
 		r.Context(),
 		"country info request completed",
-		"country_code", countryCode,
+		"country_code", country.CCA2,
+		"view", view,
+		"fields", len(fields),
+		"language", names.Language,
 	)
 }
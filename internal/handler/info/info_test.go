@@ -1,10 +1,14 @@
 package info
 
 import (
+	"countryinfo/internal/resolver"
 	"countryinfo/internal/restclient"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestInfoHandlerUsesConfiguredEndpoint(t *testing.T) {
@@ -20,7 +24,7 @@ func TestInfoHandlerUsesConfiguredEndpoint(t *testing.T) {
 	defer upstream.Close()
 
 	client := restclient.NewCountriesClient(upstream.URL + "/v3.1")
-	handler := Handler(client)
+	handler := Handler(resolver.New(client))
 	req := httptest.NewRequest(http.MethodGet, "/countryinfo/v1/info/no", nil)
 	req.SetPathValue("country_code", "no")
 	w := httptest.NewRecorder()
@@ -34,7 +38,7 @@ func TestInfoHandlerUsesConfiguredEndpoint(t *testing.T) {
 		t.Fatalf("expected upstream path /v3.1/alpha/no, got %q", gotPath)
 	}
 
-	expected := `{"name":"Norway","continents":["Europe"],"population":5379475,"area":323802,"languages":{"nno":"Norwegian Nynorsk","nob":"Norwegian Bokmal","smi":"Sami"},"borders":["FIN","SWE","RUS"],"flag":"https://flagcdn.com/w320/no.png","capital":"Oslo"}`
+	expected := `{"name":"Norway","continents":["Europe"],"population":5379475,"area":323802,"languages":{"nno":"Norwegian Nynorsk","nob":"Norwegian Bokmal","smi":"Sami"},"borders":["FIN","SWE","RUS"],"flag":"https://flagcdn.com/w320/no.png","capital":"Oslo","capitals":[{"name":"Oslo"}]}`
 	if got := w.Body.String(); got != expected {
 		t.Fatalf("unexpected response body:\ngot:  %q\nwant: %q", got, expected)
 	}
@@ -44,9 +48,9 @@ func TestInfoHandlerRejectsInvalidCountryCode(t *testing.T) {
 	t.Parallel()
 
 	client := restclient.NewCountriesClient("http://example.com")
-	handler := Handler(client)
-	req := httptest.NewRequest(http.MethodGet, "/countryinfo/v1/info/nor", nil)
-	req.SetPathValue("country_code", "nor")
+	handler := Handler(resolver.New(client))
+	req := httptest.NewRequest(http.MethodGet, "/countryinfo/v1/info/n%3Cr%3E", nil)
+	req.SetPathValue("country_code", "n<r>")
 	w := httptest.NewRecorder()
 
 	handler(w, req)
@@ -55,3 +59,289 @@ func TestInfoHandlerRejectsInvalidCountryCode(t *testing.T) {
 		t.Fatalf("expected status 400, got %d", w.Code)
 	}
 }
+
+func TestInfoHandlerFailsFastWhenCircuitIsOpen(t *testing.T) {
+	t.Parallel()
+
+	hits := 0
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer upstream.Close()
+
+	client := restclient.NewCountriesClient(
+		upstream.URL+"/v3.1",
+		restclient.WithCountriesRetry(restclient.RetryPolicy{MaxAttempts: 1}),
+		restclient.WithCountriesBreaker(restclient.BreakerSettings{FailureThreshold: 1, OpenTimeout: time.Minute}),
+	)
+	handler := Handler(resolver.New(client))
+
+	for range 2 {
+		req := httptest.NewRequest(http.MethodGet, "/countryinfo/v1/info/no", nil)
+		req.SetPathValue("country_code", "no")
+		w := httptest.NewRecorder()
+
+		handler(w, req)
+
+		if w.Code != http.StatusServiceUnavailable {
+			t.Fatalf("expected status 503, got %d", w.Code)
+		}
+	}
+	if hits != 1 {
+		t.Fatalf("expected 1 upstream request, got %d", hits)
+	}
+}
+
+func TestInfoHandlerReturnsNotFoundForUnknownCountry(t *testing.T) {
+	t.Parallel()
+
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"status":404,"message":"Not Found"}`))
+	}))
+	defer upstream.Close()
+
+	handler := Handler(resolver.New(restclient.NewCountriesClient(upstream.URL + "/v3.1")))
+	req := httptest.NewRequest(http.MethodGet, "/countryinfo/v1/info/xx", nil)
+	req.SetPathValue("country_code", "xx")
+	w := httptest.NewRecorder()
+
+	handler(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("expected status 404, got %d", w.Code)
+	}
+}
+
+func TestInfoHandlerResolvesNamesAndAliases(t *testing.T) {
+	t.Parallel()
+
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path != "/v3.1/all" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(`[
+			{"cca2":"NL","cca3":"NLD","name":{"common":"Netherlands","official":"Kingdom of the Netherlands"},"altSpellings":["NL","Holland"],"capital":["Amsterdam"]},
+			{"cca2":"KP","cca3":"PRK","name":{"common":"North Korea","official":"Democratic People's Republic of Korea"}},
+			{"cca2":"KR","cca3":"KOR","name":{"common":"South Korea","official":"Republic of Korea"}}
+		]`))
+	}))
+	defer upstream.Close()
+
+	handler := Handler(resolver.New(restclient.NewCountriesClient(upstream.URL + "/v3.1")))
+
+	req := httptest.NewRequest(http.MethodGet, "/countryinfo/v1/info/Holland", nil)
+	req.SetPathValue("country_code", "Holland")
+	w := httptest.NewRecorder()
+	handler(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", w.Code)
+	}
+	if !strings.Contains(w.Body.String(), `"name":"Netherlands"`) {
+		t.Errorf("expected the Netherlands, got %s", w.Body.String())
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/countryinfo/v1/info/korea", nil)
+	req.SetPathValue("country_code", "korea")
+	w = httptest.NewRecorder()
+	handler(w, req)
+
+	if w.Code != http.StatusMultipleChoices {
+		t.Fatalf("expected status 300, got %d", w.Code)
+	}
+	expected := `{"error":"ambiguous country","query":"korea","candidates":[{"name":"North Korea","alpha2":"KP","alpha3":"PRK"},{"name":"South Korea","alpha2":"KR","alpha3":"KOR"}]}`
+	if got := w.Body.String(); got != expected {
+		t.Errorf("unexpected response body:\ngot:  %s\nwant: %s", got, expected)
+	}
+}
+
+func TestInfoHandlerFullView(t *testing.T) {
+	t.Parallel()
+
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`[{"name":{"common":"Norway","official":"Kingdom of Norway"},"tld":[".no"],"cca2":"NO","ccn3":"578","cca3":"NOR","cioc":"NOR","independent":true,"unMember":true,"currencies":{"NOK":{"name":"Norwegian krone","symbol":"kr"}},"idd":{"root":"+4","suffixes":["7"]},"capital":["Oslo"],"region":"Europe","subregion":"Northern Europe","languages":{"nob":"Norwegian Bokmål"},"translations":{"deu":{"official":"Königreich Norwegen","common":"Norwegen"}},"latlng":[62.0,10.0],"landlocked":false,"borders":["FIN","SWE","RUS"],"area":323802.0,"demonyms":{"eng":{"f":"Norwegian","m":"Norwegian"}},"maps":{"googleMaps":"https://goo.gl/maps/htWRrphA7vNgQNdSA","openStreetMaps":"https://www.openstreetmap.org/relation/2978650"},"population":5379475,"gini":{"2018":27.6},"car":{"signs":["N"],"side":"right"},"timezones":["UTC+01:00"],"continents":["Europe"],"flags":{"png":"https://flagcdn.com/w320/no.png"},"startOfWeek":"monday","capitalInfo":{"latlng":[59.92,10.75]},"postalCode":{"format":"####","regex":"^(\\d{4})$"}}]`))
+	}))
+	defer upstream.Close()
+
+	handler := Handler(resolver.New(restclient.NewCountriesClient(upstream.URL + "/v3.1")))
+	req := httptest.NewRequest(http.MethodGet, "/countryinfo/v1/info/no?view=full", nil)
+	req.SetPathValue("country_code", "no")
+	w := httptest.NewRecorder()
+
+	handler(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", w.Code)
+	}
+	expected := `{"name":"Norway","continents":["Europe"],"population":5379475,"area":323802,"languages":{"nob":"Norwegian Bokmål"},"borders":["FIN","SWE","RUS"],"flag":"https://flagcdn.com/w320/no.png","capital":"Oslo","capitals":[{"name":"Oslo","latlng":[59.92,10.75]}],` +
+		`"official-name":"Kingdom of Norway","alpha2":"NO","alpha3":"NOR","numeric":"578","cioc":"NOR","tld":[".no"],"calling-codes":["+47"],` +
+		`"currencies":{"NOK":{"name":"Norwegian krone","symbol":"kr"}},"region":"Europe","subregion":"Northern Europe","latlng":[62,10],"capital-latlng":[59.92,10.75],` +
+		`"timezones":["UTC+01:00"],"demonyms":{"eng":{"f":"Norwegian","m":"Norwegian"}},"translations":{"deu":{"common":"Norwegen","official":"Königreich Norwegen"}},` +
+		`"gini":{"2018":27.6},"car":{"signs":["N"],"side":"right"},"postal-code":{"format":"####","regex":"^(\\d{4})$"},"landlocked":false,"independent":true,"un-member":true,` +
+		`"start-of-week":"monday","maps":{"google-maps":"https://goo.gl/maps/htWRrphA7vNgQNdSA","openstreetmap":"https://www.openstreetmap.org/relation/2978650"}}`
+	if got := w.Body.String(); got != expected {
+		t.Fatalf("unexpected response body:\ngot:  %s\nwant: %s", got, expected)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/countryinfo/v1/info/no?view=everything", nil)
+	req.SetPathValue("country_code", "no")
+	w = httptest.NewRecorder()
+
+	handler(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected status 400 for an unknown view, got %d", w.Code)
+	}
+}
+
+func TestInfoHandlerSparseFieldset(t *testing.T) {
+	t.Parallel()
+
+	gotFields := ""
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotFields = r.URL.Query().Get("fields")
+		w.Header().Set("Content-Type", "application/json")
+		// The upstream answers single lookups with ?fields= with an object.
+		_, _ = w.Write([]byte(`{"cca2":"NO","name":{"common":"Norway","official":"Kingdom of Norway"},"capital":["Oslo"],"population":5379475}`))
+	}))
+	defer upstream.Close()
+
+	handler := Handler(resolver.New(restclient.NewCountriesClient(upstream.URL + "/v3.1")))
+	req := httptest.NewRequest(http.MethodGet, "/countryinfo/v1/info/no?fields=population,name,capital,name", nil)
+	req.SetPathValue("country_code", "no")
+	w := httptest.NewRecorder()
+
+	handler(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", w.Code)
+	}
+	if gotFields != "capital,cca2,name,population" {
+		t.Errorf("expected upstream fields capital,cca2,name,population, got %q", gotFields)
+	}
+	expected := `{"population":5379475,"name":"Norway","capital":"Oslo"}`
+	if got := w.Body.String(); got != expected {
+		t.Fatalf("unexpected response body:\ngot:  %s\nwant: %s", got, expected)
+	}
+}
+
+func TestInfoHandlerRejectsUnknownFields(t *testing.T) {
+	t.Parallel()
+
+	handler := Handler(resolver.New(restclient.NewCountriesClient("http://example.com")))
+	req := httptest.NewRequest(http.MethodGet, "/countryinfo/v1/info/no?fields=name,flags,size", nil)
+	req.SetPathValue("country_code", "no")
+	w := httptest.NewRecorder()
+
+	handler(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected status 400, got %d", w.Code)
+	}
+	if body := w.Body.String(); !strings.Contains(body, "unknown fields: flags, size") || !strings.Contains(body, "valid fields are:") {
+		t.Errorf("expected the unknown and valid fields in the error, got %q", body)
+	}
+}
+
+func TestUpstreamFieldsCoverDetailedResponse(t *testing.T) {
+	t.Parallel()
+
+	var fields []string
+	collect := func(typ reflect.Type) {
+		for i := range typ.NumField() {
+			if tag := typ.Field(i).Tag.Get("json"); tag != "" {
+				fields = append(fields, strings.Split(tag, ",")[0])
+			}
+		}
+	}
+	collect(reflect.TypeOf(Response{}))
+	collect(reflect.TypeOf(DetailedResponse{}))
+
+	for _, field := range fields {
+		if _, ok := upstreamFields[field]; !ok {
+			t.Errorf("response field %q has no upstream fields", field)
+		}
+	}
+	if len(fields) != len(upstreamFields) {
+		t.Errorf("expected %d upstream field mappings, got %d", len(fields), len(upstreamFields))
+	}
+}
+
+func TestInfoHandlerLocalizesNames(t *testing.T) {
+	t.Parallel()
+
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`[{"cca2":"NO","name":{"common":"Norway","official":"Kingdom of Norway","nativeName":{"nob":{"official":"Kongeriket Norge","common":"Norge"}}},"translations":{"deu":{"official":"Königreich Norwegen","common":"Norwegen"}},"capital":["Oslo"]}]`))
+	}))
+	defer upstream.Close()
+
+	handler := Handler(resolver.New(restclient.NewCountriesClient(upstream.URL + "/v3.1")))
+	tests := []struct {
+		target         string
+		acceptLanguage string
+		wantName       string
+		wantLanguage   string
+	}{
+		{"/countryinfo/v1/info/no", "", `"name":"Norway"`, "en"},
+		{"/countryinfo/v1/info/no", "fr;q=0.9, de;q=0.8, en;q=0.5", `"name":"Norwegen"`, "de"},
+		{"/countryinfo/v1/info/no", "nb-NO, en;q=0.5", `"name":"Norge"`, "nb"},
+		{"/countryinfo/v1/info/no", "ja", `"name":"Norway"`, "en"},
+		{"/countryinfo/v1/info/no?lang=de", "nb", `"name":"Norwegen"`, "de"},
+		{"/countryinfo/v1/info/no?view=full&lang=de", "", `"official-name":"Königreich Norwegen"`, "de"},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, tt.target, nil)
+		req.SetPathValue("country_code", "no")
+		if tt.acceptLanguage != "" {
+			req.Header.Set("Accept-Language", tt.acceptLanguage)
+		}
+		w := httptest.NewRecorder()
+
+		handler(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("%s: expected status 200, got %d", tt.target, w.Code)
+		}
+		if !strings.Contains(w.Body.String(), tt.wantName) {
+			t.Errorf("%s (%s): expected %s in %s", tt.target, tt.acceptLanguage, tt.wantName, w.Body.String())
+		}
+		if got := w.Header().Get("Content-Language"); got != tt.wantLanguage {
+			t.Errorf("%s (%s): expected Content-Language %q, got %q", tt.target, tt.acceptLanguage, tt.wantLanguage, got)
+		}
+		if got := w.Header().Get("Vary"); got != "Accept-Language" {
+			t.Errorf("%s: expected Vary: Accept-Language, got %q", tt.target, got)
+		}
+	}
+}
+
+func TestInfoHandlerReturnsAllCapitals(t *testing.T) {
+	t.Parallel()
+
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`[{"cca2":"ZA","name":{"common":"South Africa"},"capital":["Pretoria","Bloemfontein","Cape Town"],"capitalInfo":{"latlng":[-25.7,28.22]}}]`))
+	}))
+	defer upstream.Close()
+
+	handler := Handler(resolver.New(restclient.NewCountriesClient(upstream.URL + "/v3.1")))
+	req := httptest.NewRequest(http.MethodGet, "/countryinfo/v1/info/za?fields=capital,capitals", nil)
+	req.SetPathValue("country_code", "za")
+	w := httptest.NewRecorder()
+
+	handler(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", w.Code)
+	}
+	expected := `{"capital":"Pretoria","capitals":[{"name":"Pretoria","latlng":[-25.7,28.22]},{"name":"Bloemfontein"},{"name":"Cape Town"}]}`
+	if got := w.Body.String(); got != expected {
+		t.Fatalf("unexpected response body:\ngot:  %s\nwant: %s", got, expected)
+	}
+}
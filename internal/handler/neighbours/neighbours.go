@@ -0,0 +1,216 @@
+package neighbours
+
+import (
+	"countryinfo/internal/borders"
+	"countryinfo/internal/fanout"
+	"countryinfo/internal/handler/info"
+	"countryinfo/internal/handler/partial"
+	"countryinfo/internal/resolver"
+	"countryinfo/internal/restclient"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Response lists the countries bordering a country.
+type Response struct {
+	Country    string      `json:"country"`
+	Alpha3     string      `json:"alpha3"`
+	Neighbours []Neighbour `json:"neighbours"`
+	Warnings   []Warning   `json:"warnings,omitempty"`
+}
+
+// Neighbour is the info record of a bordering country, with its codes.
+type Neighbour struct {
+	Alpha2 string `json:"alpha2"`
+	Alpha3 string `json:"alpha3"`
+	info.Response
+}
+
+// Warning explains why a neighbour is missing from the response.
+type Warning struct {
+	Neighbour string `json:"neighbour"`
+	Reason    string `json:"reason"`
+}
+
+// DepthResponse lists the countries reachable from a country within ?depth=
+// land crossings, grouped by the number of crossings.
+type DepthResponse struct {
+	Country string `json:"country"`
+	Alpha3  string `json:"alpha3"`
+	Depth   int    `json:"depth"`
+	// Reachable is the number of countries in Hops.
+	Reachable int       `json:"reachable"`
+	Hops      []Hop     `json:"hops"`
+	Warnings  []Warning `json:"warnings,omitempty"`
+}
+
+// Hop holds the countries exactly Distance land crossings away.
+type Hop struct {
+	Distance  int         `json:"distance"`
+	Countries []Neighbour `json:"countries"`
+}
+
+const maxDepth = 10
+
+type service struct {
+	countries   *restclient.CountriesClient
+	resolver    *resolver.Resolver
+	graph       *borders.Loader
+	concurrency int
+}
+
+// Option configures optional neighbours handler behaviour.
+type Option func(*service)
+
+// WithConcurrency bounds how many neighbour lookups run in parallel when they
+// cannot be resolved with a single batch request.
+func WithConcurrency(n int) Option {
+	return func(s *service) {
+		s.concurrency = n
+	}
+}
+
+// Handler serves the info records of the countries bordering a country.
+func Handler(countries *restclient.CountriesClient, countryResolver *resolver.Resolver, borderGraph *borders.Loader, opts ...Option) http.HandlerFunc {
+	s := &service{
+		countries:   countries,
+		resolver:    countryResolver,
+		graph:       borderGraph,
+		concurrency: fanout.DefaultLimit,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s.neighboursHandler
+}
+
+func (s *service) neighboursHandler(w http.ResponseWriter, r *http.Request) {
+	depth := 0
+	if raw := strings.TrimSpace(r.URL.Query().Get("depth")); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 1 || parsed > maxDepth {
+			http.Error(
+				w,
+				fmt.Sprintf("%s\ndepth must be an integer between 1 and %d", http.StatusText(http.StatusBadRequest), maxDepth),
+				http.StatusBadRequest,
+			)
+			return
+		}
+		depth = parsed
+	}
+
+	query := r.PathValue("country_code")
+	ctx := r.Context()
+	country, err := s.resolver.Resolve(ctx, query)
+	if err != nil {
+		resolver.WriteError(w, r, query, err)
+		return
+	}
+	if depth > 0 {
+		s.walk(w, r, country, depth)
+		return
+	}
+
+	resp := Response{
+		Country:    country.Name.Common,
+		Alpha3:     country.CCA3,
+		Neighbours: []Neighbour{},
+	}
+	if len(country.Borders) > 0 {
+		found, unresolved := borders.Resolve(ctx, s.countries, country.Borders, s.concurrency)
+		for _, n := range found {
+			resp.Neighbours = append(resp.Neighbours, newNeighbour(n.Country))
+		}
+		for _, u := range unresolved {
+			resp.Warnings = append(resp.Warnings, Warning{Neighbour: u.Code, Reason: u.Reason})
+		}
+	}
+
+	if len(resp.Warnings) > 0 {
+		w.Header().Set(partial.Header, "true")
+	}
+	writeJSON(w, r, resp)
+
+	slog.InfoContext(ctx, "neighbours request completed",
+		"country_code", country.CCA2,
+		"neighbours", len(resp.Neighbours),
+		"warnings", len(resp.Warnings),
+	)
+}
+
+// walk responds with the countries reachable from country within depth land
+// crossings, found by a breadth-first walk over the cached border graph.
+func (s *service) walk(w http.ResponseWriter, r *http.Request, country restclient.Country, depth int) {
+	ctx := r.Context()
+	graph, err := s.graph.Graph(ctx)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to load border graph", "error", err)
+		http.Error(w, restclient.ErrorMessage(err), restclient.HTTPStatus(err))
+		return
+	}
+
+	resp := DepthResponse{
+		Country: country.Name.Common,
+		Alpha3:  country.CCA3,
+		Depth:   depth,
+		Hops:    []Hop{},
+	}
+	// Borders naming unknown countries cut the walk short where they are
+	// crossed, so warn about those of every country whose borders were followed.
+	explored := []string{country.CCA3}
+	for i, codes := range graph.Within(country.CCA3, depth) {
+		hop := Hop{Distance: i + 1, Countries: make([]Neighbour, 0, len(codes))}
+		for _, code := range codes {
+			c, _ := graph.Country(code)
+			hop.Countries = append(hop.Countries, newNeighbour(c))
+		}
+		resp.Hops = append(resp.Hops, hop)
+		resp.Reachable += len(codes)
+		if hop.Distance < depth {
+			explored = append(explored, codes...)
+		}
+	}
+	warned := make(map[string]bool)
+	for _, code := range explored {
+		for _, missing := range graph.Missing(code) {
+			if !warned[missing] {
+				warned[missing] = true
+				resp.Warnings = append(resp.Warnings, Warning{Neighbour: missing, Reason: "country not found"})
+			}
+		}
+	}
+
+	if len(resp.Warnings) > 0 {
+		w.Header().Set(partial.Header, "true")
+	}
+	writeJSON(w, r, resp)
+
+	slog.InfoContext(ctx, "neighbours walk completed",
+		"country_code", country.CCA2,
+		"depth", depth,
+		"reachable", resp.Reachable,
+	)
+}
+
+func newNeighbour(c restclient.Country) Neighbour {
+	return Neighbour{
+		Alpha2:   c.CCA2,
+		Alpha3:   c.CCA3,
+		Response: info.NewResponse(c),
+	}
+}
+
+func writeJSON(w http.ResponseWriter, r *http.Request, v any) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "failed to marshal json", "error", err)
+		http.Error(w, "failed to marshal json", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(data)
+}
@@ -0,0 +1,159 @@
+package neighbours
+
+import (
+	"countryinfo/internal/borders"
+	"countryinfo/internal/handler/partial"
+	"countryinfo/internal/resolver"
+	"countryinfo/internal/restclient"
+	"countryinfo/internal/restclient/restclienttest"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync/atomic"
+	"testing"
+)
+
+// newHandler builds the handler with a resolver and border graph of its own,
+// as the router would share them between handlers.
+func newHandler(countries *restclient.CountriesClient, opts ...Option) http.HandlerFunc {
+	return Handler(countries, resolver.New(countries), borders.NewLoader(countries), opts...)
+}
+
+func TestNeighboursHandlerReturnsInfoForEachBorder(t *testing.T) {
+	t.Parallel()
+
+	countriesAPI := restclienttest.NewCountriesAPI(t, []string{
+		`{"cca2":"NO","cca3":"NOR","name":{"common":"Norway"},"borders":["FIN","SWE","RUS"]}`,
+		`{"cca2":"FI","cca3":"FIN","name":{"common":"Finland"},"capital":["Helsinki"],"continents":["Europe"],"population":5530719,"area":338424,"languages":{"fin":"Finnish"},"borders":["NOR","SWE","RUS"],"flags":{"png":"https://flagcdn.com/w320/fi.png"}}`,
+		`{"cca2":"SE","cca3":"SWE","name":{"common":"Sweden"},"capital":["Stockholm"],"continents":["Europe"],"population":10353442,"area":450295,"languages":{"swe":"Swedish"},"borders":["NOR","FIN"],"flags":{"png":"https://flagcdn.com/w320/se.png"}}`,
+	})
+	defer countriesAPI.Close()
+
+	handler := newHandler(restclient.NewCountriesClient(countriesAPI.URL + "/v3.1"))
+	req := httptest.NewRequest(http.MethodGet, "/countryinfo/v1/neighbours/no", nil)
+	req.SetPathValue("country_code", "no")
+	w := httptest.NewRecorder()
+
+	handler(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", w.Code)
+	}
+	if got := w.Header().Get(partial.Header); got != "true" {
+		t.Errorf("expected %s header to be true, got %q", partial.Header, got)
+	}
+	expected := `{"country":"Norway","alpha3":"NOR","neighbours":[` +
+		`{"alpha2":"FI","alpha3":"FIN","name":"Finland","continents":["Europe"],"population":5530719,"area":338424,"languages":{"fin":"Finnish"},"borders":["NOR","SWE","RUS"],"flag":"https://flagcdn.com/w320/fi.png","capital":"Helsinki","capitals":[{"name":"Helsinki"}]},` +
+		`{"alpha2":"SE","alpha3":"SWE","name":"Sweden","continents":["Europe"],"population":10353442,"area":450295,"languages":{"swe":"Swedish"},"borders":["NOR","FIN"],"flag":"https://flagcdn.com/w320/se.png","capital":"Stockholm","capitals":[{"name":"Stockholm"}]}],` +
+		`"warnings":[{"neighbour":"RUS","reason":"country not found"}]}`
+	if got := w.Body.String(); got != expected {
+		t.Fatalf("unexpected response body:\ngot:  %s\nwant: %s", got, expected)
+	}
+}
+
+func TestNeighboursHandlerCountryWithoutBorders(t *testing.T) {
+	t.Parallel()
+
+	countriesAPI := restclienttest.NewCountriesAPI(t, []string{
+		`{"cca2":"IS","cca3":"ISL","name":{"common":"Iceland"}}`,
+	})
+	defer countriesAPI.Close()
+
+	handler := newHandler(restclient.NewCountriesClient(countriesAPI.URL + "/v3.1"))
+	req := httptest.NewRequest(http.MethodGet, "/countryinfo/v1/neighbours/is", nil)
+	req.SetPathValue("country_code", "is")
+	w := httptest.NewRecorder()
+
+	handler(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", w.Code)
+	}
+	if got, want := w.Body.String(), `{"country":"Iceland","alpha3":"ISL","neighbours":[]}`; got != want {
+		t.Fatalf("unexpected response body:\ngot:  %s\nwant: %s", got, want)
+	}
+}
+
+func TestNeighboursHandlerWalksBorderGraph(t *testing.T) {
+	t.Parallel()
+
+	var allHits atomic.Int32
+	countriesAPI := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/v3.1/alpha/lv":
+			_, _ = w.Write([]byte(`[{"cca2":"LV","cca3":"LVA","name":{"common":"Latvia"},"borders":["EST","LTU"]}]`))
+		case "/v3.1/all":
+			allHits.Add(1)
+			_, _ = w.Write([]byte(`[
+				{"cca2":"LV","cca3":"LVA","name":{"common":"Latvia"},"borders":["EST","LTU"]},
+				{"cca2":"EE","cca3":"EST","name":{"common":"Estonia"},"borders":["LVA","RUS"]},
+				{"cca2":"LT","cca3":"LTU","name":{"common":"Lithuania"},"borders":["LVA","POL","XXX"]},
+				{"cca2":"RU","cca3":"RUS","name":{"common":"Russia"},"borders":["EST","NOR"]},
+				{"cca2":"PL","cca3":"POL","name":{"common":"Poland"},"borders":["LTU"]},
+				{"cca2":"NO","cca3":"NOR","name":{"common":"Norway"},"borders":["RUS"]}
+			]`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer countriesAPI.Close()
+
+	handler := newHandler(restclient.NewCountriesClient(countriesAPI.URL + "/v3.1"))
+	for range 2 {
+		req := httptest.NewRequest(http.MethodGet, "/countryinfo/v1/neighbours/lv?depth=2", nil)
+		req.SetPathValue("country_code", "lv")
+		w := httptest.NewRecorder()
+
+		handler(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d", w.Code)
+		}
+		var resp DepthResponse
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("invalid response body: %v", err)
+		}
+		var got [][]string
+		for i, hop := range resp.Hops {
+			if hop.Distance != i+1 {
+				t.Errorf("expected hop %d to have distance %d, got %d", i, i+1, hop.Distance)
+			}
+			var codes []string
+			for _, c := range hop.Countries {
+				codes = append(codes, c.Alpha3)
+			}
+			got = append(got, codes)
+		}
+		if want := [][]string{{"EST", "LTU"}, {"POL", "RUS"}}; !reflect.DeepEqual(got, want) {
+			t.Errorf("expected hops %v, got %v", want, got)
+		}
+		if resp.Depth != 2 || resp.Reachable != 4 {
+			t.Errorf("expected depth 2 and 4 reachable countries, got %d and %d", resp.Depth, resp.Reachable)
+		}
+		if len(resp.Warnings) != 1 || resp.Warnings[0].Neighbour != "XXX" {
+			t.Errorf("expected a warning for XXX, got %v", resp.Warnings)
+		}
+	}
+	if got := allHits.Load(); got != 1 {
+		t.Errorf("expected the country list to be fetched once, got %d", got)
+	}
+}
+
+func TestNeighboursHandlerRejectsInvalidDepth(t *testing.T) {
+	t.Parallel()
+
+	handler := newHandler(restclient.NewCountriesClient("http://example.com"))
+	for _, depth := range []string{"0", "11", "two"} {
+		req := httptest.NewRequest(http.MethodGet, "/countryinfo/v1/neighbours/no?depth="+depth, nil)
+		req.SetPathValue("country_code", "no")
+		w := httptest.NewRecorder()
+
+		handler(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("depth=%s: expected status 400, got %d", depth, w.Code)
+		}
+	}
+}
@@ -0,0 +1,6 @@
+// Package partial holds what handlers share to mark partial results.
+package partial
+
+// Header is set on responses that omit some neighbours or currencies; the
+// reasons are listed in the response's warnings.
+const Header = "X-Partial-Result"
@@ -0,0 +1,173 @@
+package route
+
+import (
+	"countryinfo/internal/borders"
+	"countryinfo/internal/resolver"
+	"countryinfo/internal/restclient"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// Response is the shortest overland route between two countries.
+type Response struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+	// Crossings is the number of borders crossed, one less than len(Path).
+	Crossings int      `json:"crossings"`
+	Path      []Stop   `json:"path"`
+	Avoid     []string `json:"avoid,omitempty"`
+}
+
+// Stop is a country on a route.
+type Stop struct {
+	Name   string `json:"name"`
+	Alpha2 string `json:"alpha2"`
+	Alpha3 string `json:"alpha3"`
+}
+
+// maxAvoid bounds the number of countries accepted by ?avoid=.
+const maxAvoid = 20
+
+type service struct {
+	resolver *resolver.Resolver
+	graph    *borders.Loader
+}
+
+func newService(countryResolver *resolver.Resolver, borderGraph *borders.Loader) *service {
+	return &service{
+		resolver: countryResolver,
+		graph:    borderGraph,
+	}
+}
+
+// Handler serves the shortest overland route between two countries.
+func Handler(countryResolver *resolver.Resolver, borderGraph *borders.Loader) http.HandlerFunc {
+	return newService(countryResolver, borderGraph).routeHandler
+}
+
+func (s *service) routeHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	fromQuery, toQuery := r.PathValue("from"), r.PathValue("to")
+	from, err := s.resolver.Resolve(ctx, fromQuery)
+	if err != nil {
+		resolver.WriteError(w, r, fromQuery, err)
+		return
+	}
+	to, err := s.resolver.Resolve(ctx, toQuery)
+	if err != nil {
+		resolver.WriteError(w, r, toQuery, err)
+		return
+	}
+
+	graph, err := s.graph.Graph(ctx)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to load border graph", "error", err)
+		http.Error(w, restclient.ErrorMessage(err), restclient.HTTPStatus(err))
+		return
+	}
+
+	avoid, ok := s.parseAvoid(w, r, graph, from, to)
+	if !ok {
+		return
+	}
+
+	path := graph.Path(from.CCA3, to.CCA3, avoid)
+	if path == nil {
+		reason := fmt.Sprintf("%s and %s are on separate landmasses", from.Name.Common, to.Name.Common)
+		if len(avoid) > 0 && graph.Path(from.CCA3, to.CCA3, nil) != nil {
+			reason = fmt.Sprintf("every overland route from %s to %s crosses an avoided country", from.Name.Common, to.Name.Common)
+		}
+		http.Error(
+			w,
+			fmt.Sprintf("%s\nno overland route: %s", http.StatusText(http.StatusNotFound), reason),
+			http.StatusNotFound,
+		)
+		return
+	}
+
+	resp := Response{
+		From:      from.Name.Common,
+		To:        to.Name.Common,
+		Crossings: len(path) - 1,
+		Path:      make([]Stop, 0, len(path)),
+	}
+	for _, code := range path {
+		resp.Path = append(resp.Path, newStop(graph, code))
+	}
+	for code := range avoid {
+		resp.Avoid = append(resp.Avoid, code)
+	}
+	slices.Sort(resp.Avoid)
+
+	data, err := json.Marshal(resp)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to marshal json", "error", err)
+		http.Error(w, "failed to marshal json", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(data)
+
+	slog.InfoContext(ctx, "route request completed",
+		"from", from.CCA3,
+		"to", to.CCA3,
+		"crossings", resp.Crossings,
+	)
+}
+
+// parseAvoid resolves the comma-separated countries of ?avoid= to their
+// alpha-3 codes. Codes are looked up in graph; only other entries, such as
+// names and aliases, go through the resolver. It writes an error response and
+// returns false if there are more than maxAvoid entries, or one cannot be
+// resolved or is an end of the route.
+func (s *service) parseAvoid(w http.ResponseWriter, r *http.Request, graph *borders.Graph, from, to restclient.Country) (map[string]bool, bool) {
+	raw := strings.TrimSpace(r.URL.Query().Get("avoid"))
+	if raw == "" {
+		return nil, true
+	}
+
+	var queries []string
+	for _, query := range strings.Split(raw, ",") {
+		if query = strings.TrimSpace(query); query != "" {
+			queries = append(queries, query)
+		}
+	}
+	if len(queries) > maxAvoid {
+		badRequest(w, fmt.Sprintf("avoid accepts at most %d countries", maxAvoid))
+		return nil, false
+	}
+
+	avoid := make(map[string]bool, len(queries))
+	for _, query := range queries {
+		country, ok := graph.Lookup(query)
+		if !ok {
+			var err error
+			country, err = s.resolver.Resolve(r.Context(), query)
+			if err != nil {
+				var ambiguous *resolver.AmbiguousError
+				if errors.Is(err, resolver.ErrInvalidQuery) || errors.Is(err, resolver.ErrUnknownCountry) ||
+					errors.Is(err, restclient.ErrNotFound) || errors.As(err, &ambiguous) {
+					badRequest(w, fmt.Sprintf("invalid avoid country: %s", query))
+				} else {
+					resolver.WriteError(w, r, query, err)
+				}
+				return nil, false
+			}
+		}
+		if country.CCA3 == from.CCA3 || country.CCA3 == to.CCA3 {
+			badRequest(w, fmt.Sprintf("cannot avoid the start or end of the route: %s", query))
+			return nil, false
+		}
+		avoid[country.CCA3] = true
+	}
+	return avoid, true
+}
+
+func badRequest(w http.ResponseWriter, msg string) {
+	http.Error(w, fmt.Sprintf("%s\n%s", http.StatusText(http.StatusBadRequest), msg), http.StatusBadRequest)
+}
@@ -0,0 +1,152 @@
+package route
+
+import (
+	"countryinfo/internal/borders"
+	"countryinfo/internal/resolver"
+	"countryinfo/internal/restclient"
+	"countryinfo/internal/restclient/restclienttest"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+var testCountries = []string{
+	`{"cca2":"NO","cca3":"NOR","name":{"common":"Norway"},"borders":["FIN","SWE","RUS"]}`,
+	`{"cca2":"SE","cca3":"SWE","name":{"common":"Sweden"},"borders":["NOR","FIN"]}`,
+	`{"cca2":"FI","cca3":"FIN","name":{"common":"Finland"},"borders":["NOR","SWE","RUS"]}`,
+	`{"cca2":"RU","cca3":"RUS","name":{"common":"Russia"},"borders":["NOR","FIN","EST"]}`,
+	`{"cca2":"EE","cca3":"EST","name":{"common":"Estonia"},"borders":["RUS","LVA"]}`,
+	`{"cca2":"LV","cca3":"LVA","name":{"common":"Latvia"},"borders":["EST"]}`,
+	`{"cca2":"IS","cca3":"ISL","name":{"common":"Iceland"}}`,
+	`{"cca2":"CH","cca3":"CHE","name":{"common":"Switzerland"},"landlocked":true,"borders":["AUT","LIE"]}`,
+	`{"cca2":"AT","cca3":"AUT","name":{"common":"Austria"},"landlocked":true,"borders":["CHE","DEU","LIE"]}`,
+	`{"cca2":"LI","cca3":"LIE","name":{"common":"Liechtenstein"},"landlocked":true,"borders":["AUT","CHE","DEU"]}`,
+	`{"cca2":"DE","cca3":"DEU","name":{"common":"Germany"},"borders":["AUT","LIE"]}`,
+	`{"cca2":"VA","cca3":"VAT","name":{"common":"Vatican City"},"landlocked":true}`,
+}
+
+// newHandler builds the route handler with a resolver and border graph of its
+// own, as the router would share them between handlers.
+func newHandler(countries *restclient.CountriesClient) http.HandlerFunc {
+	return Handler(resolver.New(countries), borders.NewLoader(countries))
+}
+
+// newSeaHandler is newHandler for the sea handler.
+func newSeaHandler(countries *restclient.CountriesClient) http.HandlerFunc {
+	return SeaHandler(resolver.New(countries), borders.NewLoader(countries))
+}
+
+func serveRoute(t *testing.T, handler http.HandlerFunc, from, to, query string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	req := httptest.NewRequest(http.MethodGet, "/countryinfo/v1/route/"+from+"/"+to+query, nil)
+	req.SetPathValue("from", from)
+	req.SetPathValue("to", to)
+	w := httptest.NewRecorder()
+	handler(w, req)
+	return w
+}
+
+func TestRouteHandlerFindsShortestPath(t *testing.T) {
+	t.Parallel()
+
+	countriesAPI := restclienttest.NewCountriesAPI(t, testCountries)
+	defer countriesAPI.Close()
+
+	w := serveRoute(t, newHandler(restclient.NewCountriesClient(countriesAPI.URL+"/v3.1")), "se", "lv", "")
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	expected := `{"from":"Sweden","to":"Latvia","crossings":4,"path":[` +
+		`{"name":"Sweden","alpha2":"SE","alpha3":"SWE"},` +
+		`{"name":"Finland","alpha2":"FI","alpha3":"FIN"},` +
+		`{"name":"Russia","alpha2":"RU","alpha3":"RUS"},` +
+		`{"name":"Estonia","alpha2":"EE","alpha3":"EST"},` +
+		`{"name":"Latvia","alpha2":"LV","alpha3":"LVA"}]}`
+	if got := w.Body.String(); got != expected {
+		t.Fatalf("unexpected response body:\ngot:  %s\nwant: %s", got, expected)
+	}
+}
+
+func TestRouteHandlerAvoidsCountries(t *testing.T) {
+	t.Parallel()
+
+	countriesAPI := restclienttest.NewCountriesAPI(t, testCountries)
+	defer countriesAPI.Close()
+
+	w := serveRoute(t, newHandler(restclient.NewCountriesClient(countriesAPI.URL+"/v3.1")), "swe", "rus", "?avoid=fi")
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	expected := `{"from":"Sweden","to":"Russia","crossings":2,"path":[` +
+		`{"name":"Sweden","alpha2":"SE","alpha3":"SWE"},` +
+		`{"name":"Norway","alpha2":"NO","alpha3":"NOR"},` +
+		`{"name":"Russia","alpha2":"RU","alpha3":"RUS"}],"avoid":["FIN"]}`
+	if got := w.Body.String(); got != expected {
+		t.Fatalf("unexpected response body:\ngot:  %s\nwant: %s", got, expected)
+	}
+}
+
+func TestRouteHandlerResolvesAvoidedCodesFromGraph(t *testing.T) {
+	t.Parallel()
+
+	countriesAPI := restclienttest.NewCountriesAPI(t, testCountries)
+	defer countriesAPI.Close()
+	var lookups atomic.Int32
+	countingAPI := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, "/v3.1/alpha/") {
+			lookups.Add(1)
+		}
+		countriesAPI.Config.Handler.ServeHTTP(w, r)
+	}))
+	defer countingAPI.Close()
+
+	w := serveRoute(t, newHandler(restclient.NewCountriesClient(countingAPI.URL+"/v3.1")), "swe", "rus", "?avoid=fi,EE,Latvia")
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if !strings.HasSuffix(w.Body.String(), `"avoid":["EST","FIN","LVA"]}`) {
+		t.Errorf("unexpected response body: %s", w.Body.String())
+	}
+	// Only the two ends of the route are looked up by code upstream.
+	if got := lookups.Load(); got != 2 {
+		t.Errorf("expected 2 code lookups, got %d", got)
+	}
+}
+
+func TestRouteHandlerNoRoute(t *testing.T) {
+	t.Parallel()
+
+	countriesAPI := restclienttest.NewCountriesAPI(t, testCountries)
+	defer countriesAPI.Close()
+	handler := newHandler(restclient.NewCountriesClient(countriesAPI.URL + "/v3.1"))
+
+	tests := []struct {
+		name, from, to, query string
+		status                int
+		reason                string
+	}{
+		{"separate landmasses", "no", "is", "", http.StatusNotFound, "Norway and Iceland are on separate landmasses"},
+		{"every route avoided", "no", "lv", "?avoid=RUS", http.StatusNotFound, "every overland route from Norway to Latvia crosses an avoided country"},
+		{"unknown avoided country", "no", "lv", "?avoid=xyz", http.StatusBadRequest, "invalid avoid country: xyz"},
+		{"avoided end", "no", "lv", "?avoid=lva", http.StatusBadRequest, "cannot avoid the start or end of the route: lva"},
+		{"too many avoided countries", "no", "lv", "?avoid=" + strings.Repeat("fin,", maxAvoid+1), http.StatusBadRequest, "avoid accepts at most 20 countries"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := serveRoute(t, handler, tt.from, tt.to, tt.query)
+
+			if w.Code != tt.status {
+				t.Fatalf("expected status %d, got %d", tt.status, w.Code)
+			}
+			if got := w.Body.String(); !strings.Contains(got, tt.reason) {
+				t.Errorf("expected body to contain %q, got %q", tt.reason, got)
+			}
+		})
+	}
+}
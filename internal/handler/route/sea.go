@@ -0,0 +1,101 @@
+package route
+
+import (
+	"countryinfo/internal/borders"
+	"countryinfo/internal/resolver"
+	"countryinfo/internal/restclient"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+)
+
+// SeaResponse lists the shortest overland routes from a country to the coast.
+type SeaResponse struct {
+	Country    string `json:"country"`
+	Alpha3     string `json:"alpha3"`
+	Landlocked bool   `json:"landlocked"`
+	// Crossings is the minimum number of borders crossed to reach a coastal
+	// country, 0 for coastal countries.
+	Crossings         int      `json:"crossings"`
+	CoastalNeighbours []Stop   `json:"coastal-neighbours"`
+	Paths             [][]Stop `json:"paths"`
+}
+
+// SeaHandler serves every shortest overland route from a country to a
+// coastal country.
+func SeaHandler(countryResolver *resolver.Resolver, borderGraph *borders.Loader) http.HandlerFunc {
+	return newService(countryResolver, borderGraph).seaHandler
+}
+
+func (s *service) seaHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	query := r.PathValue("country_code")
+	country, err := s.resolver.Resolve(ctx, query)
+	if err != nil {
+		resolver.WriteError(w, r, query, err)
+		return
+	}
+
+	graph, err := s.graph.Graph(ctx)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to load border graph", "error", err)
+		http.Error(w, restclient.ErrorMessage(err), restclient.HTTPStatus(err))
+		return
+	}
+
+	coastal := func(code string) bool {
+		c, ok := graph.Country(code)
+		return ok && !c.Landlocked
+	}
+	paths := graph.ShortestPaths(country.CCA3, coastal)
+	if paths == nil {
+		http.Error(
+			w,
+			fmt.Sprintf("%s\nno overland route to the sea from %s", http.StatusText(http.StatusNotFound), country.Name.Common),
+			http.StatusNotFound,
+		)
+		return
+	}
+
+	resp := SeaResponse{
+		Country:           country.Name.Common,
+		Alpha3:            country.CCA3,
+		Landlocked:        country.Landlocked,
+		Crossings:         len(paths[0]) - 1,
+		CoastalNeighbours: []Stop{},
+		Paths:             make([][]Stop, 0, len(paths)),
+	}
+	for _, code := range graph.Neighbours(country.CCA3) {
+		if coastal(code) {
+			resp.CoastalNeighbours = append(resp.CoastalNeighbours, newStop(graph, code))
+		}
+	}
+	for _, path := range paths {
+		stops := make([]Stop, 0, len(path))
+		for _, code := range path {
+			stops = append(stops, newStop(graph, code))
+		}
+		resp.Paths = append(resp.Paths, stops)
+	}
+
+	data, err := json.Marshal(resp)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to marshal json", "error", err)
+		http.Error(w, "failed to marshal json", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(data)
+
+	slog.InfoContext(ctx, "sea route request completed",
+		"country_code", country.CCA2,
+		"crossings", resp.Crossings,
+		"paths", len(resp.Paths),
+	)
+}
+
+func newStop(graph *borders.Graph, code string) Stop {
+	c, _ := graph.Country(code)
+	return Stop{Name: c.Name.Common, Alpha2: c.CCA2, Alpha3: c.CCA3}
+}
@@ -0,0 +1,75 @@
+package route
+
+import (
+	"countryinfo/internal/restclient"
+	"countryinfo/internal/restclient/restclienttest"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func serveSea(t *testing.T, handler http.HandlerFunc, code string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	req := httptest.NewRequest(http.MethodGet, "/countryinfo/v1/sea/"+code, nil)
+	req.SetPathValue("country_code", code)
+	w := httptest.NewRecorder()
+	handler(w, req)
+	return w
+}
+
+func TestSeaHandlerListsEveryShortestPath(t *testing.T) {
+	t.Parallel()
+
+	countriesAPI := restclienttest.NewCountriesAPI(t, testCountries)
+	defer countriesAPI.Close()
+
+	w := serveSea(t, newSeaHandler(restclient.NewCountriesClient(countriesAPI.URL+"/v3.1")), "ch")
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	expected := `{"country":"Switzerland","alpha3":"CHE","landlocked":true,"crossings":2,` +
+		`"coastal-neighbours":[],"paths":[` +
+		`[{"name":"Switzerland","alpha2":"CH","alpha3":"CHE"},{"name":"Austria","alpha2":"AT","alpha3":"AUT"},{"name":"Germany","alpha2":"DE","alpha3":"DEU"}],` +
+		`[{"name":"Switzerland","alpha2":"CH","alpha3":"CHE"},{"name":"Liechtenstein","alpha2":"LI","alpha3":"LIE"},{"name":"Germany","alpha2":"DE","alpha3":"DEU"}]]}`
+	if got := w.Body.String(); got != expected {
+		t.Fatalf("unexpected response body:\ngot:  %s\nwant: %s", got, expected)
+	}
+}
+
+func TestSeaHandlerCoastalNeighbours(t *testing.T) {
+	t.Parallel()
+
+	countriesAPI := restclienttest.NewCountriesAPI(t, testCountries)
+	defer countriesAPI.Close()
+
+	w := serveSea(t, newSeaHandler(restclient.NewCountriesClient(countriesAPI.URL+"/v3.1")), "at")
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	expected := `{"country":"Austria","alpha3":"AUT","landlocked":true,"crossings":1,` +
+		`"coastal-neighbours":[{"name":"Germany","alpha2":"DE","alpha3":"DEU"}],"paths":[` +
+		`[{"name":"Austria","alpha2":"AT","alpha3":"AUT"},{"name":"Germany","alpha2":"DE","alpha3":"DEU"}]]}`
+	if got := w.Body.String(); got != expected {
+		t.Fatalf("unexpected response body:\ngot:  %s\nwant: %s", got, expected)
+	}
+}
+
+func TestSeaHandlerNoRouteToTheSea(t *testing.T) {
+	t.Parallel()
+
+	countriesAPI := restclienttest.NewCountriesAPI(t, testCountries)
+	defer countriesAPI.Close()
+
+	w := serveSea(t, newSeaHandler(restclient.NewCountriesClient(countriesAPI.URL+"/v3.1")), "va")
+
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("expected status 404, got %d", w.Code)
+	}
+	if got, want := w.Body.String(), "no overland route to the sea from Vatican City"; !strings.Contains(got, want) {
+		t.Errorf("expected body to contain %q, got %q", want, got)
+	}
+}
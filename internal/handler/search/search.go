@@ -0,0 +1,95 @@
+package search
+
+import (
+	"countryinfo/internal/resolver"
+	"countryinfo/internal/restclient"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	defaultLimit   = 10
+	maxLimit       = 50
+	maxQueryLength = 100
+)
+
+// Response lists the countries matching a search query, best matches first.
+type Response struct {
+	Query   string              `json:"query"`
+	Mode    resolver.SearchMode `json:"mode"`
+	Results []resolver.Match    `json:"results"`
+}
+
+type service struct {
+	resolver *resolver.Resolver
+}
+
+// Handler serves typo-tolerant country search over names, official names,
+// alternative spellings, capitals, native names and translations.
+func Handler(countryResolver *resolver.Resolver) http.HandlerFunc {
+	s := &service{
+		resolver: countryResolver,
+	}
+	return s.searchHandler
+}
+
+func (s *service) searchHandler(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	q := strings.TrimSpace(query.Get("q"))
+	if q == "" || utf8.RuneCountInString(q) > maxQueryLength {
+		badRequest(w, fmt.Sprintf("q must be between 1 and %d characters", maxQueryLength))
+		return
+	}
+
+	mode := resolver.SearchMode(strings.ToLower(strings.TrimSpace(query.Get("mode"))))
+	switch mode {
+	case "":
+		mode = resolver.SearchFuzzy
+	case resolver.SearchFuzzy, resolver.SearchPrefix:
+	default:
+		badRequest(w, fmt.Sprintf("invalid mode: %s (expected fuzzy or prefix)", mode))
+		return
+	}
+
+	limit := defaultLimit
+	if raw := strings.TrimSpace(query.Get("limit")); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 1 || parsed > maxLimit {
+			badRequest(w, fmt.Sprintf("limit must be an integer between 1 and %d", maxLimit))
+			return
+		}
+		limit = parsed
+	}
+
+	ctx := r.Context()
+	matches, err := s.resolver.Search(ctx, q, mode, limit)
+	if err != nil {
+		slog.ErrorContext(ctx, "country search failed", "error", err, "query", q)
+		http.Error(w, restclient.ErrorMessage(err), restclient.HTTPStatus(err))
+		return
+	}
+
+	data, err := json.Marshal(Response{Query: q, Mode: mode, Results: matches})
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to marshal json", "error", err)
+		http.Error(w, "failed to marshal json", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(data)
+
+	slog.InfoContext(ctx, "search request completed",
+		"query", q,
+		"mode", mode,
+		"results", len(matches),
+	)
+}
+
+func badRequest(w http.ResponseWriter, msg string) {
+	http.Error(w, fmt.Sprintf("%s\n%s", http.StatusText(http.StatusBadRequest), msg), http.StatusBadRequest)
+}
@@ -0,0 +1,67 @@
+package search
+
+import (
+	"countryinfo/internal/resolver"
+	"countryinfo/internal/restclient"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSearchHandlerRanksMatches(t *testing.T) {
+	t.Parallel()
+
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v3.1/all" {
+			t.Errorf("unexpected upstream path %q", r.URL.Path)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`[
+			{"cca2":"SE","cca3":"SWE","ccn3":"752","name":{"common":"Sweden","official":"Kingdom of Sweden"},"capital":["Stockholm"]},
+			{"cca2":"CH","cca3":"CHE","ccn3":"756","name":{"common":"Switzerland","official":"Swiss Confederation"},"capital":["Bern"]}
+		]`))
+	}))
+	defer upstream.Close()
+
+	handler := Handler(resolver.New(restclient.NewCountriesClient(upstream.URL + "/v3.1")))
+	req := httptest.NewRequest(http.MethodGet, "/countryinfo/v1/search?q=swedn", nil)
+	w := httptest.NewRecorder()
+
+	handler(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", w.Code)
+	}
+	var resp Response
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("invalid response body: %v", err)
+	}
+	if resp.Mode != "fuzzy" || len(resp.Results) != 1 {
+		t.Fatalf("unexpected response: %+v", resp)
+	}
+	if got := resp.Results[0]; got.Alpha2 != "SE" || got.Alpha3 != "SWE" || got.Numeric != "752" || got.Matched != "Sweden" {
+		t.Errorf("unexpected result: %+v", got)
+	}
+}
+
+func TestSearchHandlerRejectsInvalidParameters(t *testing.T) {
+	t.Parallel()
+
+	handler := Handler(resolver.New(restclient.NewCountriesClient("http://example.com")))
+	for _, target := range []string{
+		"/countryinfo/v1/search",
+		"/countryinfo/v1/search?q=no&mode=exact",
+		"/countryinfo/v1/search?q=no&limit=0",
+		"/countryinfo/v1/search?q=no&limit=many",
+	} {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		w := httptest.NewRecorder()
+
+		handler(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected status 400, got %d", target, w.Code)
+		}
+	}
+}
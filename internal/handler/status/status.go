@@ -2,6 +2,7 @@ package status
 
 import (
 	"context"
+	"countryinfo/internal/cache"
 	"countryinfo/internal/config"
 	"countryinfo/internal/restclient"
 	"encoding/json"
@@ -20,10 +21,12 @@ const (
 )
 
 type serviceHealth struct {
-	CountryAPI  int    `json:"restcountriesapi"`
-	CurrencyAPI int    `json:"currenciesapi"`
-	Version     string `json:"version"`
-	Uptime      int    `json:"uptime"`
+	CountryAPI  int                                   `json:"restcountriesapi"`
+	CurrencyAPI int                                   `json:"currenciesapi"`
+	Version     string                                `json:"version"`
+	Uptime      int                                   `json:"uptime"`
+	Breakers    map[string]restclient.BreakerSnapshot `json:"breakers"`
+	Caches      map[string]cache.Stats                `json:"caches"`
 }
 
 type service struct {
@@ -31,14 +34,18 @@ type service struct {
 	countryProbeURL  string
 	currencyProbeURL string
 	startTime        time.Time
+	countries        *restclient.CountriesClient
+	currencies       *restclient.CurrencyClient
 }
 
-func Handler(cfg *config.Config) http.HandlerFunc {
+func Handler(cfg *config.Config, countries *restclient.CountriesClient, currencies *restclient.CurrencyClient) http.HandlerFunc {
 	s := &service{
 		client:           &http.Client{Timeout: statusProbeTimeout},
 		countryProbeURL:  probeURL(cfg.CountriesEndpoint, countryProbePath),
 		currencyProbeURL: probeURL(cfg.CurrencyEndpoint, currencyProbePath),
 		startTime:        time.Now(),
+		countries:        countries,
+		currencies:       currencies,
 	}
 	return s.statusHandler
 }
@@ -52,6 +59,14 @@ func (s *service) newServiceHealth(ctx context.Context) (*serviceHealth, error)
 		CurrencyAPI: currencyStatusCode,
 		Version:     apiVersion,
 		Uptime:      int(time.Since(s.startTime).Seconds()),
+		Breakers: map[string]restclient.BreakerSnapshot{
+			"restcountriesapi": s.countries.Breaker().Snapshot(),
+			"currenciesapi":    s.currencies.Breaker().Snapshot(),
+		},
+		Caches: map[string]cache.Stats{
+			"restcountriesapi": s.countries.CacheStats(),
+			"currenciesapi":    s.currencies.CacheStats(),
+		},
 	}, errors.Join(countryErr, currencyErr)
 }
 
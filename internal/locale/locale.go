@@ -0,0 +1,165 @@
+// Package locale negotiates the language of country names from
+// Accept-Language style language preferences.
+package locale
+
+import (
+	"countryinfo/internal/restclient"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// English is the language of the upstream's default country names.
+const English = "en"
+
+// Preference is a language range with its quality value.
+type Preference struct {
+	Range string
+	Q     float64
+}
+
+// ParsePreferences parses an Accept-Language header value (RFC 9110, section
+// 12.5.4): comma-separated language ranges, each with an optional ";q="
+// weight. Ranges are lower-cased and returned most preferred first, keeping
+// the given order between equal weights. Malformed ranges and ranges with a
+// weight of zero are dropped.
+func ParsePreferences(header string) []Preference {
+	var prefs []Preference
+	for _, part := range strings.Split(header, ",") {
+		langRange, params, _ := strings.Cut(part, ";")
+		langRange = strings.ToLower(strings.TrimSpace(langRange))
+		if !isLanguageRange(langRange) {
+			continue
+		}
+
+		q := 1.0
+		if params = strings.TrimSpace(params); params != "" {
+			name, value, ok := strings.Cut(params, "=")
+			if !ok || strings.TrimSpace(name) != "q" {
+				continue
+			}
+			parsed, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+			if err != nil || parsed < 0 || parsed > 1 {
+				continue
+			}
+			q = parsed
+		}
+		if q == 0 {
+			continue
+		}
+		prefs = append(prefs, Preference{Range: langRange, Q: q})
+	}
+
+	slices.SortStableFunc(prefs, func(a, b Preference) int {
+		switch {
+		case a.Q > b.Q:
+			return -1
+		case a.Q < b.Q:
+			return 1
+		}
+		return 0
+	})
+	return prefs
+}
+
+// isLanguageRange reports whether s is "*" or subtags of one to eight ASCII
+// letters or digits joined by hyphens, starting with a letter.
+func isLanguageRange(s string) bool {
+	if s == "*" {
+		return true
+	}
+	for i, subtag := range strings.Split(s, "-") {
+		if len(subtag) < 1 || len(subtag) > 8 {
+			return false
+		}
+		for _, r := range subtag {
+			isLetter := r >= 'a' && r <= 'z'
+			if !isLetter && (i == 0 || r < '0' || r > '9') {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// upstreamLanguages maps ISO 639-1 codes to the ISO 639-2/3 codes the
+// upstream keys translations and native names by.
+var upstreamLanguages = map[string][]string{
+	"ar": {"ara"},
+	"bg": {"bul"},
+	"br": {"bre"},
+	"cs": {"ces"},
+	"cy": {"cym"},
+	"da": {"dan"},
+	"de": {"deu"},
+	"el": {"ell"},
+	"es": {"spa"},
+	"et": {"est"},
+	"fa": {"per", "fas"},
+	"fi": {"fin"},
+	"fr": {"fra"},
+	"ga": {"gle"},
+	"he": {"heb"},
+	"hi": {"hin"},
+	"hr": {"hrv"},
+	"hu": {"hun"},
+	"id": {"ind"},
+	"is": {"isl"},
+	"it": {"ita"},
+	"ja": {"jpn"},
+	"ko": {"kor"},
+	"lt": {"lit"},
+	"lv": {"lav"},
+	"nb": {"nob"},
+	"nl": {"nld"},
+	"nn": {"nno"},
+	"no": {"nob", "nno"},
+	"pl": {"pol"},
+	"pt": {"por"},
+	"ro": {"ron"},
+	"ru": {"rus"},
+	"se": {"sme", "smi"},
+	"sk": {"slk"},
+	"sl": {"slv"},
+	"sr": {"srp"},
+	"sv": {"swe"},
+	"tr": {"tur"},
+	"uk": {"ukr"},
+	"ur": {"urd"},
+	"zh": {"zho"},
+}
+
+// Names are a country's names in one language.
+type Names struct {
+	Common   string
+	Official string
+	// Language is the language tag of the names, e.g. "de" or "en".
+	Language string
+}
+
+// Select returns the country's names in the most preferred language the
+// upstream has them in, from its translations or, for the country's own
+// languages, its native names. It falls back to English.
+func Select(c restclient.Country, prefs []Preference) Names {
+	english := Names{Common: c.Name.Common, Official: c.Name.Official, Language: English}
+	for _, pref := range prefs {
+		primary, _, _ := strings.Cut(pref.Range, "-")
+		if primary == "*" || primary == English {
+			return english
+		}
+
+		codes := upstreamLanguages[primary]
+		if len(primary) == 3 {
+			codes = []string{primary}
+		}
+		for _, code := range codes {
+			if name, ok := c.Translations[code]; ok && name.Common != "" {
+				return Names{Common: name.Common, Official: name.Official, Language: primary}
+			}
+			if name, ok := c.Name.NativeName[code]; ok && name.Common != "" {
+				return Names{Common: name.Common, Official: name.Official, Language: primary}
+			}
+		}
+	}
+	return english
+}
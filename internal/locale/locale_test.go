@@ -0,0 +1,66 @@
+package locale
+
+import (
+	"countryinfo/internal/restclient"
+	"reflect"
+	"testing"
+)
+
+func TestParsePreferences(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		header string
+		want   []Preference
+	}{
+		{"", nil},
+		{"de", []Preference{{"de", 1}}},
+		{"nb-NO, nb;q=0.9, en;q=0.8", []Preference{{"nb-no", 1}, {"nb", 0.9}, {"en", 0.8}}},
+		{"en;q=0.5, de;q=0.7, fr", []Preference{{"fr", 1}, {"de", 0.7}, {"en", 0.5}}},
+		{"sv;q=0.8, da;q=0.8", []Preference{{"sv", 0.8}, {"da", 0.8}}},
+		{"de;q=0, fr", []Preference{{"fr", 1}}},
+		{"*;q=0.1, es", []Preference{{"es", 1}, {"*", 0.1}}},
+		{"de;q=abc, 12, fr-, it;q=2, pt", []Preference{{"pt", 1}}},
+	}
+	for _, tt := range tests {
+		if got := ParsePreferences(tt.header); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParsePreferences(%q) = %v, want %v", tt.header, got, tt.want)
+		}
+	}
+}
+
+func TestSelect(t *testing.T) {
+	t.Parallel()
+
+	var norway restclient.Country
+	norway.Name = restclient.Name{
+		Common:   "Norway",
+		Official: "Kingdom of Norway",
+		NativeName: map[string]restclient.CountryName{
+			"nob": {Common: "Norge", Official: "Kongeriket Norge"},
+			"nno": {Common: "Noreg", Official: "Kongeriket Noreg"},
+		},
+	}
+	norway.Translations = map[string]restclient.CountryName{
+		"deu": {Common: "Norwegen", Official: "Königreich Norwegen"},
+	}
+
+	tests := []struct {
+		header string
+		want   Names
+	}{
+		{"", Names{"Norway", "Kingdom of Norway", "en"}},
+		{"de-DE,de;q=0.9", Names{"Norwegen", "Königreich Norwegen", "de"}},
+		{"nn", Names{"Noreg", "Kongeriket Noreg", "nn"}},
+		{"no", Names{"Norge", "Kongeriket Norge", "no"}},
+		{"ja, de;q=0.5", Names{"Norwegen", "Königreich Norwegen", "de"}},
+		{"ja, en;q=0.8, de;q=0.5", Names{"Norway", "Kingdom of Norway", "en"}},
+		{"deu", Names{"Norwegen", "Königreich Norwegen", "deu"}},
+		{"xx", Names{"Norway", "Kingdom of Norway", "en"}},
+	}
+	for _, tt := range tests {
+		if got := Select(norway, ParsePreferences(tt.header)); got != tt.want {
+			t.Errorf("Select(%q) = %+v, want %+v", tt.header, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package resolver
+
+import (
+	"strings"
+	"unicode"
+)
+
+// transliterations maps lower-case Latin letters with diacritics, and a few
+// ligatures, to their plain ASCII spelling.
+var transliterations = func() map[rune]string {
+	groups := map[string]string{
+		"a":  "àáâãäåāăą",
+		"ae": "æ",
+		"c":  "çćĉċč",
+		"d":  "ďđð",
+		"e":  "èéêëēĕėęě",
+		"g":  "ĝğġģ",
+		"h":  "ĥħ",
+		"i":  "ìíîïĩīĭįı",
+		"j":  "ĵ",
+		"k":  "ķ",
+		"l":  "ĺļľŀł",
+		"n":  "ñńņňŉ",
+		"o":  "òóôõöøōŏő",
+		"oe": "œ",
+		"r":  "ŕŗř",
+		"s":  "śŝşšș",
+		"ss": "ß",
+		"t":  "ţťŧț",
+		"th": "þ",
+		"u":  "ùúûüũūŭůűų",
+		"w":  "ŵ",
+		"y":  "ýÿŷ",
+		"z":  "źżž",
+	}
+	m := make(map[rune]string)
+	for plain, accented := range groups {
+		for _, r := range accented {
+			m[r] = plain
+		}
+	}
+	return m
+}()
+
+// fold normalises a country name for comparison: it is lower-cased and
+// transliterated to ASCII, punctuation is dropped, hyphens become spaces and
+// a leading "the" is removed, so "Côte d'Ivoire" and "cote divoire" or
+// "The Gambia" and "gambia" fold to the same string.
+func fold(s string) string {
+	var b strings.Builder
+	b.Grow(len(s))
+	space := true // drop leading spaces
+	for _, r := range strings.ToLower(s) {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			if plain, ok := transliterations[r]; ok {
+				b.WriteString(plain)
+			} else {
+				b.WriteRune(r)
+			}
+			space = false
+		case unicode.IsSpace(r) || r == '-' || r == '_' || r == '/':
+			if !space {
+				b.WriteByte(' ')
+				space = true
+			}
+		}
+	}
+	folded := strings.TrimSuffix(b.String(), " ")
+	return strings.TrimPrefix(folded, "the ")
+}
@@ -0,0 +1,51 @@
+package resolver
+
+import (
+	"countryinfo/internal/restclient"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+)
+
+// AmbiguousResponse is the 300 Multiple Choices body listing the countries an
+// ambiguous query matched.
+type AmbiguousResponse struct {
+	Error      string      `json:"error"`
+	Query      string      `json:"query"`
+	Candidates []Candidate `json:"candidates"`
+}
+
+// WriteError writes the response for an error returned by Resolve: 400 for
+// invalid input, 404 for unknown countries, 300 with the candidates for
+// ambiguous names, and the upstream status for upstream failures.
+func WriteError(w http.ResponseWriter, r *http.Request, query string, err error) {
+	var ambiguous *AmbiguousError
+	switch {
+	case errors.As(err, &ambiguous):
+		data, err := json.Marshal(AmbiguousResponse{
+			Error:      "ambiguous country",
+			Query:      ambiguous.Query,
+			Candidates: ambiguous.Candidates,
+		})
+		if err != nil {
+			http.Error(w, "failed to marshal json", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusMultipleChoices)
+		_, _ = w.Write(data)
+	case errors.Is(err, ErrInvalidQuery):
+		http.Error(
+			w,
+			fmt.Sprintf("%s\ninvalid country: %s", http.StatusText(http.StatusBadRequest), query),
+			http.StatusBadRequest,
+		)
+	case errors.Is(err, ErrUnknownCountry), errors.Is(err, restclient.ErrNotFound):
+		http.Error(w, "country not found", http.StatusNotFound)
+	default:
+		slog.ErrorContext(r.Context(), "failed to resolve country", "error", err, "country", query)
+		http.Error(w, restclient.ErrorMessage(err), restclient.HTTPStatus(err))
+	}
+}
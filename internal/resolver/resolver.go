@@ -0,0 +1,257 @@
+// Package resolver maps the many ways a country can be identified, such as
+// ISO 3166 codes, common and official names and well-known aliases, onto a
+// single upstream country.
+package resolver
+
+import (
+	"context"
+	"countryinfo/internal/restclient"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+const (
+	maxQueryLength = 100
+	// minPartialLength is the shortest query matched against the start of
+	// the words in country names rather than whole names only.
+	minPartialLength = 3
+)
+
+var (
+	// ErrInvalidQuery is returned for input that cannot identify a country.
+	ErrInvalidQuery = errors.New("invalid country")
+	// ErrUnknownCountry is returned when no country matches the input.
+	ErrUnknownCountry = errors.New("unknown country")
+)
+
+// aliases maps common names that the upstream does not list among a
+// country's alternative spellings to the country's alpha-3 code.
+var aliases = map[string]string{
+	"uk":             "GBR",
+	"england":        "GBR",
+	"scotland":       "GBR",
+	"wales":          "GBR",
+	"britain":        "GBR",
+	"great britain":  "GBR",
+	"holland":        "NLD",
+	"czech republic": "CZE",
+	"ivory coast":    "CIV",
+	"burma":          "MMR",
+	"swaziland":      "SWZ",
+	"macedonia":      "MKD",
+	"east timor":     "TLS",
+	"cape verde":     "CPV",
+	"vatican":        "VAT",
+}
+
+// Candidate identifies one of several countries an ambiguous query matched.
+type Candidate struct {
+	Name   string `json:"name"`
+	Alpha2 string `json:"alpha2"`
+	Alpha3 string `json:"alpha3"`
+}
+
+// AmbiguousError is returned when a query matches more than one country.
+type AmbiguousError struct {
+	Query      string
+	Candidates []Candidate
+}
+
+func (e *AmbiguousError) Error() string {
+	return fmt.Sprintf("%q matches %d countries", e.Query, len(e.Candidates))
+}
+
+// Resolver resolves country identifiers. Codes are looked up directly; names
+// and aliases are matched against an index of all countries, built from the
+// upstream country list and rebuilt whenever the client refreshes it.
+type Resolver struct {
+	countries *restclient.CountriesClient
+
+	mu     sync.Mutex
+	source *restclient.Country // first element of the indexed country list
+	index  *index
+}
+
+// New creates a Resolver backed by countries.
+func New(countries *restclient.CountriesClient) *Resolver {
+	return &Resolver{countries: countries}
+}
+
+// Resolve returns the country identified by query: an alpha-2, alpha-3 or
+// numeric ISO 3166 code, a common or official name, or an alias such as "UK"
+// or "Holland". Names are compared case- and accent-insensitively. It returns
+// ErrInvalidQuery, ErrUnknownCountry, an *AmbiguousError listing the
+// candidates, or the upstream error. Countries looked up by code only have
+// the given upstream fields set, if any are given.
+func (r *Resolver) Resolve(ctx context.Context, query string, fields ...string) (restclient.Country, error) {
+	query = strings.TrimSpace(query)
+	if !isValidQuery(query) {
+		return restclient.Country{}, fmt.Errorf("%w: %q", ErrInvalidQuery, query)
+	}
+
+	code, isCode := normaliseCode(query)
+	if isCode {
+		countries, err := r.countries.GetByAlpha(ctx, code, fields...)
+		if err != nil && !errors.Is(err, restclient.ErrNotFound) {
+			return restclient.Country{}, err
+		}
+		if len(countries) > 0 {
+			return countries[0], nil
+		}
+	}
+
+	idx, err := r.loadIndex(ctx)
+	if err != nil {
+		if isCode {
+			// The code is unknown; without the index it cannot be an alias.
+			return restclient.Country{}, fmt.Errorf("%w: %q", ErrUnknownCountry, query)
+		}
+		return restclient.Country{}, err
+	}
+	return idx.resolve(query)
+}
+
+// loadIndex returns the name index, rebuilding it if the upstream country list
+// has changed since it was last built.
+func (r *Resolver) loadIndex(ctx context.Context) (*index, error) {
+	countries, err := r.countries.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(countries) == 0 {
+		return nil, fmt.Errorf("%w: empty country list", ErrUnknownCountry)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	// The client caches the list, so an unchanged list is the same slice.
+	if r.index == nil || r.source != &countries[0] {
+		r.index = newIndex(countries)
+		r.source = &countries[0]
+	}
+	return r.index, nil
+}
+
+// isValidQuery reports whether query looks like a country code or name.
+func isValidQuery(query string) bool {
+	if query == "" || len(query) > maxQueryLength {
+		return false
+	}
+	for _, r := range query {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && !unicode.IsSpace(r) && !strings.ContainsRune("'’.,-()&", r) {
+			return false
+		}
+	}
+	return true
+}
+
+// normaliseCode returns query as a lower-case alpha code, or a zero-padded
+// numeric code, and whether it has the shape of one.
+func normaliseCode(query string) (string, bool) {
+	switch {
+	case (len(query) == 2 || len(query) == 3) && strings.IndexFunc(query, notASCIILetter) < 0:
+		return strings.ToLower(query), true
+	case len(query) <= 3 && strings.IndexFunc(query, notASCIIDigit) < 0:
+		return fmt.Sprintf("%03s", query), true
+	}
+	return "", false
+}
+
+func notASCIILetter(r rune) bool {
+	return (r < 'a' || r > 'z') && (r < 'A' || r > 'Z')
+}
+
+func notASCIIDigit(r rune) bool {
+	return r < '0' || r > '9'
+}
+
+// index holds every country under its codes and folded names.
+type index struct {
+	countries []restclient.Country
+	names     [][]string // folded common and official name of each country
+	terms     [][]term   // searchable names of each country
+	byCode    map[string]int
+	byName    map[string][]int
+}
+
+func newIndex(countries []restclient.Country) *index {
+	idx := &index{
+		countries: countries,
+		names:     make([][]string, len(countries)),
+		terms:     make([][]term, len(countries)),
+		byCode:    make(map[string]int, 3*len(countries)),
+		byName:    make(map[string][]int, 4*len(countries)),
+	}
+	for i, c := range countries {
+		for _, code := range []string{c.CCA2, c.CCA3, c.CCN3} {
+			if code != "" {
+				idx.byCode[strings.ToLower(code)] = i
+			}
+		}
+		idx.names[i] = []string{fold(c.Name.Common), fold(c.Name.Official)}
+		idx.terms[i] = searchTerms(c)
+		idx.addName(c.Name.Common, i)
+		idx.addName(c.Name.Official, i)
+		for _, spelling := range c.AltSpellings {
+			idx.addName(spelling, i)
+		}
+	}
+	for alias, code := range aliases {
+		if i, ok := idx.byCode[strings.ToLower(code)]; ok {
+			idx.addName(alias, i)
+		}
+	}
+	return idx
+}
+
+func (idx *index) addName(name string, i int) {
+	key := fold(name)
+	if key != "" && !slices.Contains(idx.byName[key], i) {
+		idx.byName[key] = append(idx.byName[key], i)
+	}
+}
+
+// resolve looks query up by code, then by exact folded name or alias, and
+// finally by the start of a word in the common or official name.
+func (idx *index) resolve(query string) (restclient.Country, error) {
+	if code, ok := normaliseCode(query); ok {
+		if i, ok := idx.byCode[code]; ok {
+			return idx.countries[i], nil
+		}
+	}
+
+	key := fold(query)
+	matches := idx.byName[key]
+	if len(matches) == 0 && len(key) >= minPartialLength {
+		for i, names := range idx.names {
+			if slices.ContainsFunc(names, func(name string) bool { return hasWordPrefix(name, key) }) {
+				matches = append(matches, i)
+			}
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return restclient.Country{}, fmt.Errorf("%w: %q", ErrUnknownCountry, query)
+	case 1:
+		return idx.countries[matches[0]], nil
+	}
+
+	candidates := make([]Candidate, 0, len(matches))
+	for _, i := range matches {
+		c := idx.countries[i]
+		candidates = append(candidates, Candidate{Name: c.Name.Common, Alpha2: c.CCA2, Alpha3: c.CCA3})
+	}
+	slices.SortFunc(candidates, func(a, b Candidate) int { return strings.Compare(a.Name, b.Name) })
+	return restclient.Country{}, &AmbiguousError{Query: query, Candidates: candidates}
+}
+
+// hasWordPrefix reports whether one of the words of name, or a run of them,
+// starts with prefix.
+func hasWordPrefix(name, prefix string) bool {
+	return strings.HasPrefix(name, prefix) || strings.Contains(name, " "+prefix)
+}
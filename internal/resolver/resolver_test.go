@@ -0,0 +1,123 @@
+package resolver
+
+import (
+	"context"
+	"countryinfo/internal/restclient"
+	"countryinfo/internal/restclient/restclienttest"
+	"errors"
+	"testing"
+)
+
+var testCountries = []string{
+	`{"cca2":"NO","cca3":"NOR","ccn3":"578","name":{"common":"Norway","official":"Kingdom of Norway"},"altSpellings":["NO","Norge","Noreg"]}`,
+	`{"cca2":"NL","cca3":"NLD","ccn3":"528","name":{"common":"Netherlands","official":"Kingdom of the Netherlands"},"altSpellings":["NL","Nederland","The Netherlands"]}`,
+	`{"cca2":"GB","cca3":"GBR","ccn3":"826","name":{"common":"United Kingdom","official":"United Kingdom of Great Britain and Northern Ireland"},"altSpellings":["GB"]}`,
+	`{"cca2":"CI","cca3":"CIV","ccn3":"384","name":{"common":"Ivory Coast","official":"Republic of Côte d'Ivoire"},"altSpellings":["CI","Côte d'Ivoire"]}`,
+	`{"cca2":"KP","cca3":"PRK","ccn3":"408","name":{"common":"North Korea","official":"Democratic People's Republic of Korea"},"altSpellings":["KP"]}`,
+	`{"cca2":"KR","cca3":"KOR","ccn3":"410","name":{"common":"South Korea","official":"Republic of Korea"},"altSpellings":["KR"]}`,
+	`{"cca2":"AT","cca3":"AUT","ccn3":"040","name":{"common":"Austria","official":"Republic of Austria"},"altSpellings":["AT"]}`,
+}
+
+func TestResolve(t *testing.T) {
+	t.Parallel()
+
+	upstream := restclienttest.NewCountriesAPI(t, testCountries)
+	defer upstream.Close()
+	r := New(restclient.NewCountriesClient(upstream.URL + "/v3.1"))
+
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"no", "NOR"},
+		{"NOR", "NOR"},
+		{"578", "NOR"},
+		{"40", "AUT"},
+		{"Norway", "NOR"},
+		{"  norge ", "NOR"},
+		{"Kingdom of Norway", "NOR"},
+		{"Holland", "NLD"},
+		{"the netherlands", "NLD"},
+		{"UK", "GBR"},
+		{"great britain", "GBR"},
+		{"cote d'ivoire", "CIV"},
+		{"Côte d’Ivoire", "CIV"},
+		{"aust", "AUT"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			country, err := r.Resolve(context.Background(), tt.query)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if country.CCA3 != tt.want {
+				t.Errorf("expected %s, got %s", tt.want, country.CCA3)
+			}
+		})
+	}
+}
+
+func TestResolveErrors(t *testing.T) {
+	t.Parallel()
+
+	upstream := restclienttest.NewCountriesAPI(t, testCountries)
+	defer upstream.Close()
+	r := New(restclient.NewCountriesClient(upstream.URL + "/v3.1"))
+
+	tests := []struct {
+		query string
+		want  error
+	}{
+		{"", ErrInvalidQuery},
+		{"n<r>", ErrInvalidQuery},
+		{"xx", ErrUnknownCountry},
+		{"999", ErrUnknownCountry},
+		{"Atlantis", ErrUnknownCountry},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			if _, err := r.Resolve(context.Background(), tt.query); !errors.Is(err, tt.want) {
+				t.Errorf("expected %v, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestResolveAmbiguousName(t *testing.T) {
+	t.Parallel()
+
+	upstream := restclienttest.NewCountriesAPI(t, testCountries)
+	defer upstream.Close()
+	r := New(restclient.NewCountriesClient(upstream.URL + "/v3.1"))
+
+	_, err := r.Resolve(context.Background(), "Korea")
+	var ambiguous *AmbiguousError
+	if !errors.As(err, &ambiguous) {
+		t.Fatalf("expected an ambiguity error, got %v", err)
+	}
+	if len(ambiguous.Candidates) != 2 ||
+		ambiguous.Candidates[0].Alpha3 != "PRK" ||
+		ambiguous.Candidates[1].Alpha3 != "KOR" {
+		t.Errorf("unexpected candidates: %+v", ambiguous.Candidates)
+	}
+}
+
+func TestFold(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		in, want string
+	}{
+		{"Norway", "norway"},
+		{"  The  Gambia ", "gambia"},
+		{"Côte d'Ivoire", "cote divoire"},
+		{"Guinea-Bissau", "guinea bissau"},
+		{"Åland Islands", "aland islands"},
+		{"São Tomé and Príncipe", "sao tome and principe"},
+	}
+	for _, tt := range tests {
+		if got := fold(tt.in); got != tt.want {
+			t.Errorf("fold(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,210 @@
+package resolver
+
+import (
+	"cmp"
+	"context"
+	"countryinfo/internal/restclient"
+	"slices"
+	"strings"
+)
+
+// SearchMode selects how search queries are matched against country names.
+type SearchMode string
+
+const (
+	// SearchFuzzy tolerates typos as well as matching prefixes and substrings.
+	SearchFuzzy SearchMode = "fuzzy"
+	// SearchPrefix only matches names, or words in them, starting with the
+	// query, for type-ahead inputs.
+	SearchPrefix SearchMode = "prefix"
+)
+
+// Fields a search term can come from, in order of preference when a country
+// matches through several of them equally well.
+const (
+	FieldName         = "name"
+	FieldOfficialName = "official-name"
+	FieldAltSpelling  = "alt-spelling"
+	FieldCapital      = "capital"
+	FieldNativeName   = "native-name"
+	FieldTranslation  = "translation"
+)
+
+var fieldRank = map[string]int{
+	FieldName:         0,
+	FieldOfficialName: 1,
+	FieldAltSpelling:  2,
+	FieldCapital:      3,
+	FieldNativeName:   4,
+	FieldTranslation:  5,
+}
+
+// Scores of the match kinds; lower is better. Typo matches add their edit
+// distance to the base score.
+const (
+	scoreExact      = 0
+	scorePrefix     = 1
+	scoreWordPrefix = 2
+	scoreSubstring  = 3
+	scoreTypo       = 3
+	scoreTypoPrefix = 4
+)
+
+// Match is a country found by Search.
+type Match struct {
+	Candidate
+	Numeric string `json:"numeric"`
+	// Matched is the name, in its original spelling, the query matched.
+	Matched string `json:"matched"`
+	// Field is the kind of name Matched is, e.g. "capital".
+	Field string `json:"field"`
+	// Score ranks the match; lower is better and 0 is an exact match.
+	Score int `json:"score"`
+}
+
+// term is a searchable name of a country.
+type term struct {
+	folded   string
+	original string
+	field    string
+}
+
+// Search returns up to limit countries whose names, official names,
+// alternative spellings, capitals, native names or translations match query,
+// best matches first. Matching ignores case, accents and punctuation.
+func (r *Resolver) Search(ctx context.Context, query string, mode SearchMode, limit int) ([]Match, error) {
+	idx, err := r.loadIndex(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return idx.search(fold(query), mode, limit), nil
+}
+
+func (idx *index) search(query string, mode SearchMode, limit int) []Match {
+	if query == "" {
+		return []Match{}
+	}
+
+	matches := make([]Match, 0, limit)
+	for i, terms := range idx.terms {
+		best, bestTerm := -1, term{}
+		for _, t := range terms {
+			score, ok := matchScore(query, t.folded, mode)
+			if !ok {
+				continue
+			}
+			if best < 0 || score < best || (score == best && fieldRank[t.field] < fieldRank[bestTerm.field]) {
+				best, bestTerm = score, t
+			}
+		}
+		if best < 0 {
+			continue
+		}
+		c := idx.countries[i]
+		matches = append(matches, Match{
+			Candidate: Candidate{Name: c.Name.Common, Alpha2: c.CCA2, Alpha3: c.CCA3},
+			Numeric:   c.CCN3,
+			Matched:   bestTerm.original,
+			Field:     bestTerm.field,
+			Score:     best,
+		})
+	}
+
+	slices.SortFunc(matches, func(a, b Match) int {
+		return cmp.Or(
+			cmp.Compare(a.Score, b.Score),
+			cmp.Compare(fieldRank[a.Field], fieldRank[b.Field]),
+			strings.Compare(a.Name, b.Name),
+		)
+	})
+	if len(matches) > limit {
+		matches = matches[:limit]
+	}
+	return matches
+}
+
+// matchScore scores how well the folded query matches the folded name.
+func matchScore(query, name string, mode SearchMode) (int, bool) {
+	switch {
+	case name == query:
+		return scoreExact, true
+	case strings.HasPrefix(name, query):
+		return scorePrefix, true
+	case strings.Contains(name, " "+query):
+		return scoreWordPrefix, true
+	case mode == SearchPrefix:
+		return 0, false
+	case strings.Contains(name, query):
+		return scoreSubstring, true
+	}
+
+	q, n := []rune(query), []rune(name)
+	maxDistance := maxEditDistance(len(q))
+	if maxDistance == 0 {
+		return 0, false
+	}
+	best, ok := 0, false
+	if abs(len(n)-len(q)) <= maxDistance {
+		if d := editDistance(q, n); d <= maxDistance {
+			best, ok = scoreTypo+d, true
+		}
+	}
+	// A misspelt start of a longer name, as typed into a search box.
+	if len(n) > len(q) {
+		if d := editDistance(q, n[:len(q)]); d <= maxDistance && (!ok || scoreTypoPrefix+d < best) {
+			best, ok = scoreTypoPrefix+d, true
+		}
+	}
+	return best, ok
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// searchTerms lists the distinct searchable names of a country.
+func searchTerms(c restclient.Country) []term {
+	var terms []term
+	seen := make(map[string]struct{})
+	add := func(name, field string) {
+		folded := fold(name)
+		if folded == "" {
+			return
+		}
+		if _, dup := seen[folded]; dup {
+			return
+		}
+		seen[folded] = struct{}{}
+		terms = append(terms, term{folded: folded, original: name, field: field})
+	}
+
+	add(c.Name.Common, FieldName)
+	add(c.Name.Official, FieldOfficialName)
+	for _, name := range c.AltSpellings {
+		add(name, FieldAltSpelling)
+	}
+	for _, name := range c.Capital {
+		add(name, FieldCapital)
+	}
+	for _, lang := range sortedKeys(c.Name.NativeName) {
+		add(c.Name.NativeName[lang].Common, FieldNativeName)
+		add(c.Name.NativeName[lang].Official, FieldNativeName)
+	}
+	for _, lang := range sortedKeys(c.Translations) {
+		add(c.Translations[lang].Common, FieldTranslation)
+		add(c.Translations[lang].Official, FieldTranslation)
+	}
+	return terms
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
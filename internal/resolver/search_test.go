@@ -0,0 +1,94 @@
+package resolver
+
+import (
+	"countryinfo/internal/restclient"
+	"encoding/json"
+	"testing"
+)
+
+const searchCountries = `[
+	{"cca2":"NO","cca3":"NOR","ccn3":"578","name":{"common":"Norway","official":"Kingdom of Norway","nativeName":{"nob":{"common":"Norge","official":"Kongeriket Norge"}}},"capital":["Oslo"],"translations":{"deu":{"common":"Norwegen","official":"Königreich Norwegen"}}},
+	{"cca2":"NG","cca3":"NGA","ccn3":"566","name":{"common":"Nigeria","official":"Federal Republic of Nigeria"},"capital":["Abuja"]},
+	{"cca2":"NE","cca3":"NER","ccn3":"562","name":{"common":"Niger","official":"Republic of Niger"},"capital":["Niamey"]},
+	{"cca2":"DE","cca3":"DEU","ccn3":"276","name":{"common":"Germany","official":"Federal Republic of Germany","nativeName":{"deu":{"common":"Deutschland","official":"Bundesrepublik Deutschland"}}},"capital":["Berlin"],"translations":{"fra":{"common":"Allemagne","official":"République fédérale d'Allemagne"}}},
+	{"cca2":"CI","cca3":"CIV","ccn3":"384","name":{"common":"Ivory Coast","official":"Republic of Côte d'Ivoire"},"capital":["Yamoussoukro"]}
+]`
+
+func newSearchIndex(t *testing.T) *index {
+	t.Helper()
+
+	var countries []restclient.Country
+	if err := json.Unmarshal([]byte(searchCountries), &countries); err != nil {
+		t.Fatalf("invalid test countries: %v", err)
+	}
+	return newIndex(countries)
+}
+
+func TestSearch(t *testing.T) {
+	t.Parallel()
+
+	idx := newSearchIndex(t)
+	tests := []struct {
+		name      string
+		query     string
+		mode      SearchMode
+		wantFirst string
+		wantField string
+		wantCount int
+	}{
+		{"exact name", "norway", SearchFuzzy, "NOR", FieldName, 1},
+		{"typo", "norwya", SearchFuzzy, "NOR", FieldName, 1},
+		{"typo in prefix", "germna", SearchFuzzy, "DEU", FieldName, 1},
+		{"capital", "oslo", SearchFuzzy, "NOR", FieldCapital, 1},
+		{"native name", "deutschland", SearchFuzzy, "DEU", FieldNativeName, 1},
+		{"translation", "allemagne", SearchFuzzy, "DEU", FieldTranslation, 1},
+		{"accents", "COTE D’IVOIRE", SearchFuzzy, "CIV", FieldOfficialName, 1},
+		{"prefix ranks shorter exact name first", "niger", SearchPrefix, "NER", FieldName, 2},
+		{"prefix mode ignores typos", "norwya", SearchPrefix, "", "", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got := idx.search(fold(tt.query), tt.mode, 10)
+			if len(got) != tt.wantCount {
+				t.Fatalf("expected %d results, got %+v", tt.wantCount, got)
+			}
+			if tt.wantCount == 0 {
+				return
+			}
+			if got[0].Alpha3 != tt.wantFirst || got[0].Field != tt.wantField {
+				t.Errorf("expected %s via %s first, got %+v", tt.wantFirst, tt.wantField, got[0])
+			}
+		})
+	}
+}
+
+func TestSearchLimit(t *testing.T) {
+	t.Parallel()
+
+	idx := newSearchIndex(t)
+	if got := idx.search(fold("republic"), SearchFuzzy, 2); len(got) != 2 {
+		t.Fatalf("expected 2 results, got %d", len(got))
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"", "abc", 3},
+		{"norway", "norway", 0},
+		{"norwya", "norway", 1},
+		{"sweden", "swedn", 1},
+		{"kitten", "sitting", 3},
+	}
+	for _, tt := range tests {
+		if got := editDistance([]rune(tt.a), []rune(tt.b)); got != tt.want {
+			t.Errorf("editDistance(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
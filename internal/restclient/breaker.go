@@ -0,0 +1,187 @@
+package restclient
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+const (
+	defaultBreakerFailureThreshold = 5
+	defaultBreakerOpenTimeout      = 30 * time.Second
+)
+
+// ErrCircuitOpen is returned without contacting the upstream while its
+// circuit breaker is open.
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// BreakerState is the state of a circuit breaker.
+type BreakerState int
+
+const (
+	// BreakerClosed lets every request through.
+	BreakerClosed BreakerState = iota
+	// BreakerOpen rejects every request until the open timeout has passed.
+	BreakerOpen
+	// BreakerHalfOpen lets a single probe request through to decide whether
+	// to close or re-open the circuit.
+	BreakerHalfOpen
+)
+
+func (s BreakerState) String() string {
+	switch s {
+	case BreakerClosed:
+		return "closed"
+	case BreakerOpen:
+		return "open"
+	case BreakerHalfOpen:
+		return "half-open"
+	default:
+		return fmt.Sprintf("BreakerState(%d)", int(s))
+	}
+}
+
+func (s BreakerState) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// BreakerSettings controls when a Breaker trips and how long it stays open.
+type BreakerSettings struct {
+	// FailureThreshold is the number of consecutive failures that opens the
+	// circuit. Values below 1 disable the breaker.
+	FailureThreshold int
+	// OpenTimeout is how long the circuit stays open before a probe is allowed.
+	OpenTimeout time.Duration
+}
+
+// DefaultBreakerSettings is used by clients that are not given explicit settings.
+var DefaultBreakerSettings = BreakerSettings{
+	FailureThreshold: defaultBreakerFailureThreshold,
+	OpenTimeout:      defaultBreakerOpenTimeout,
+}
+
+// BreakerSnapshot is a point-in-time view of a Breaker, suitable for diagnostics.
+type BreakerSnapshot struct {
+	State     BreakerState `json:"state"`
+	Failures  int          `json:"consecutive-failures"`
+	ChangedAt time.Time    `json:"changed-at"`
+}
+
+// Breaker is a circuit breaker guarding a single upstream. It is safe for
+// concurrent use.
+type Breaker struct {
+	name     string
+	settings BreakerSettings
+	now      func() time.Time
+
+	mu            sync.Mutex
+	state         BreakerState
+	failures      int
+	changedAt     time.Time
+	probeInFlight bool
+}
+
+// NewBreaker creates a closed Breaker for the named upstream.
+func NewBreaker(name string, settings BreakerSettings) *Breaker {
+	return &Breaker{
+		name:      name,
+		settings:  settings,
+		now:       time.Now,
+		changedAt: time.Now(),
+	}
+}
+
+// Allow reports whether a request may be sent. It returns an error wrapping
+// ErrCircuitOpen if not. Every allowed request must be followed by exactly one
+// call to Success, Failure or Abandon.
+func (b *Breaker) Allow() error {
+	if b.settings.FailureThreshold < 1 {
+		return nil
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case BreakerOpen:
+		if b.now().Sub(b.changedAt) < b.settings.OpenTimeout {
+			return fmt.Errorf("%s: %w", b.name, ErrCircuitOpen)
+		}
+		b.transition(BreakerHalfOpen)
+		b.probeInFlight = true
+		return nil
+	case BreakerHalfOpen:
+		if b.probeInFlight {
+			return fmt.Errorf("%s: %w", b.name, ErrCircuitOpen)
+		}
+		b.probeInFlight = true
+		return nil
+	default:
+		return nil
+	}
+}
+
+// Success records a successful request, closing the circuit if it was half-open.
+func (b *Breaker) Success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures = 0
+	b.probeInFlight = false
+	if b.state != BreakerClosed {
+		b.transition(BreakerClosed)
+	}
+}
+
+// Failure records a failed request, opening the circuit once the failure
+// threshold is reached or immediately if the failed request was a probe.
+func (b *Breaker) Failure() {
+	if b.settings.FailureThreshold < 1 {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures++
+	b.probeInFlight = false
+	if b.state == BreakerHalfOpen || (b.state == BreakerClosed && b.failures >= b.settings.FailureThreshold) {
+		b.transition(BreakerOpen)
+	}
+}
+
+// Abandon releases an allowed request whose outcome says nothing about the
+// upstream's health, such as one cancelled by the caller.
+func (b *Breaker) Abandon() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == BreakerHalfOpen {
+		b.probeInFlight = false
+	}
+}
+
+// Snapshot returns the breaker's current state.
+func (b *Breaker) Snapshot() BreakerSnapshot {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return BreakerSnapshot{
+		State:     b.state,
+		Failures:  b.failures,
+		ChangedAt: b.changedAt,
+	}
+}
+
+func (b *Breaker) transition(to BreakerState) {
+	slog.Warn("circuit breaker state changed",
+		"upstream", b.name,
+		"from", b.state.String(),
+		"to", to.String(),
+		"consecutive_failures", b.failures,
+	)
+	b.state = to
+	b.changedAt = b.now()
+}
@@ -0,0 +1,88 @@
+package restclient
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestBreakerOpensAfterConsecutiveFailures(t *testing.T) {
+	b := NewBreaker("test", BreakerSettings{FailureThreshold: 2, OpenTimeout: time.Minute})
+
+	for range 2 {
+		if err := b.Allow(); err != nil {
+			t.Fatalf("expected closed breaker to allow request, got %v", err)
+		}
+		b.Failure()
+	}
+
+	if got := b.Snapshot().State; got != BreakerOpen {
+		t.Fatalf("expected state open, got %s", got)
+	}
+	if err := b.Allow(); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected ErrCircuitOpen, got %v", err)
+	}
+}
+
+func TestBreakerSuccessResetsFailureCount(t *testing.T) {
+	b := NewBreaker("test", BreakerSettings{FailureThreshold: 2, OpenTimeout: time.Minute})
+
+	_ = b.Allow()
+	b.Failure()
+	_ = b.Allow()
+	b.Success()
+	_ = b.Allow()
+	b.Failure()
+
+	if got := b.Snapshot().State; got != BreakerClosed {
+		t.Fatalf("expected state closed, got %s", got)
+	}
+}
+
+func TestBreakerHalfOpenAllowsSingleProbe(t *testing.T) {
+	now := time.Now()
+	b := NewBreaker("test", BreakerSettings{FailureThreshold: 1, OpenTimeout: time.Second})
+	b.now = func() time.Time { return now }
+
+	_ = b.Allow()
+	b.Failure()
+
+	now = now.Add(2 * time.Second)
+	if err := b.Allow(); err != nil {
+		t.Fatalf("expected probe to be allowed, got %v", err)
+	}
+	if got := b.Snapshot().State; got != BreakerHalfOpen {
+		t.Fatalf("expected state half-open, got %s", got)
+	}
+	if err := b.Allow(); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected second request during probe to be rejected, got %v", err)
+	}
+
+	b.Success()
+	if got := b.Snapshot().State; got != BreakerClosed {
+		t.Fatalf("expected state closed after successful probe, got %s", got)
+	}
+}
+
+func TestBreakerFailedProbeReopens(t *testing.T) {
+	now := time.Now()
+	b := NewBreaker("test", BreakerSettings{FailureThreshold: 3, OpenTimeout: time.Second})
+	b.now = func() time.Time { return now }
+
+	for range 3 {
+		_ = b.Allow()
+		b.Failure()
+	}
+
+	now = now.Add(2 * time.Second)
+	_ = b.Allow()
+	b.Failure()
+
+	snapshot := b.Snapshot()
+	if snapshot.State != BreakerOpen {
+		t.Fatalf("expected state open after failed probe, got %s", snapshot.State)
+	}
+	if !snapshot.ChangedAt.Equal(now) {
+		t.Errorf("expected changed-at %v, got %v", now, snapshot.ChangedAt)
+	}
+}
@@ -1,92 +1,303 @@
 package restclient
 
 import (
+	"bytes"
 	"context"
+	"countryinfo/internal/cache"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
+	"slices"
 	"strings"
 	"time"
 )
 
 const (
+	countriesUpstreamName    = "countries"
 	countriesUpstreamPath    = "alpha/"
+	countriesBatchPath       = "alpha"
+	countriesAllPath         = "all"
 	countriesUpstreamTimeout = 5 * time.Second
+
+	// allCountriesKey is the key of the full country list. It cannot clash
+	// with a country code.
+	allCountriesKey = "*"
+)
+
+// Defaults of the country cache, used unless WithCountriesCache is given.
+const (
+	DefaultCountriesCacheSize   = 512
+	DefaultCountriesCacheTTL    = 24 * time.Hour
+	DefaultCountriesNotFoundTTL = 5 * time.Minute
 )
 
-// Country represents the upstream REST Countries API response shape.
-type Country struct {
-	Name struct {
-		Common string `json:"common"`
-	} `json:"name"`
-	Currencies map[string]struct {
-		Name   string `json:"name"`
-		Symbol string `json:"symbol"`
-	} `json:"currencies"`
-	Capital    []string          `json:"capital"`
-	Languages  map[string]string `json:"languages"`
-	Borders    []string          `json:"borders"`
-	Area       float64           `json:"area"`
-	Population int               `json:"population"`
-	Continents []string          `json:"continents"`
-	Flags      struct {
-		Png string `json:"png"`
-		Svg string `json:"svg"`
-		Alt string `json:"alt"`
-	} `json:"flags"`
+// countriesEntry is what the country cache stores: either a successful
+// lookup or a remembered upstream 404.
+type countriesEntry struct {
+	countries []Country
+	err       error
 }
 
 // CountriesClient handles HTTP communication with the REST Countries API.
 type CountriesClient struct {
-	client  *http.Client
-	baseURL string
+	client      *http.Client
+	baseURL     string
+	cache       *cache.Cache[string, countriesEntry]
+	all         *cache.Cache[string, countriesEntry] // full country list, kept apart so lookups cannot evict it
+	notFoundTTL time.Duration
+	inflight    cache.Group[string, countriesEntry]
+	retry       RetryPolicy
+	breaker     *Breaker
+}
+
+// CountriesOption configures optional CountriesClient behaviour.
+type CountriesOption func(*CountriesClient)
+
+// WithCountriesCache sets the size and freshness of the country lookup cache.
+// ttl applies to successful lookups and the full country list, and
+// notFoundTTL to upstream 404s. A size of zero disables caching.
+func WithCountriesCache(size int, ttl, notFoundTTL time.Duration) CountriesOption {
+	return func(c *CountriesClient) {
+		c.cache = cache.New[string, countriesEntry](size, ttl)
+		c.all = cache.New[string, countriesEntry](min(size, 1), ttl)
+		c.notFoundTTL = notFoundTTL
+	}
+}
+
+// WithCountriesRetry sets the retry policy for requests to the countries upstream.
+func WithCountriesRetry(policy RetryPolicy) CountriesOption {
+	return func(c *CountriesClient) {
+		c.retry = policy
+	}
+}
+
+// WithCountriesBreaker sets the circuit breaker settings for the countries upstream.
+func WithCountriesBreaker(settings BreakerSettings) CountriesOption {
+	return func(c *CountriesClient) {
+		c.breaker = NewBreaker(countriesUpstreamName, settings)
+	}
 }
 
 // NewCountriesClient creates a CountriesClient for the given base URL.
 // The base URL should include the version path, e.g. "http://129.241.150.113:8080/v3.1".
-func NewCountriesClient(baseURL string) *CountriesClient {
+func NewCountriesClient(baseURL string, opts ...CountriesOption) *CountriesClient {
 	cleaned := strings.TrimSpace(baseURL)
 	if cleaned != "" {
 		cleaned = strings.TrimRight(cleaned, "/") + "/"
 	}
-	return &CountriesClient{
-		client:  &http.Client{Timeout: countriesUpstreamTimeout},
-		baseURL: cleaned,
+	c := &CountriesClient{
+		client:      &http.Client{Timeout: countriesUpstreamTimeout},
+		baseURL:     cleaned,
+		cache:       cache.New[string, countriesEntry](DefaultCountriesCacheSize, DefaultCountriesCacheTTL),
+		all:         cache.New[string, countriesEntry](1, DefaultCountriesCacheTTL),
+		notFoundTTL: DefaultCountriesNotFoundTTL,
+		retry:       DefaultRetryPolicy,
+		breaker:     NewBreaker(countriesUpstreamName, DefaultBreakerSettings),
+	}
+	for _, opt := range opts {
+		opt(c)
 	}
+	return c
+}
+
+// CacheStats reports hit, miss and eviction counters for the country cache.
+func (c *CountriesClient) CacheStats() cache.Stats {
+	return c.cache.Stats()
+}
+
+// Breaker returns the circuit breaker guarding the countries upstream.
+func (c *CountriesClient) Breaker() *Breaker {
+	return c.breaker
 }
 
 // GetByAlpha fetches country information by a two-letter country code.
-func (c *CountriesClient) GetByAlpha(ctx context.Context, countryCode string) ([]Country, error) {
+// Results, including upstream 404s, are cached and concurrent lookups of the
+// same code share a single upstream request. If fields are given, only those
+// upstream fields are requested and the other fields of the result are empty.
+func (c *CountriesClient) GetByAlpha(ctx context.Context, countryCode string, fields ...string) ([]Country, error) {
+	key := strings.ToLower(countryCode)
+	if len(fields) == 0 {
+		return c.lookup(ctx, c.cache, key, countriesUpstreamPath+key)
+	}
+
+	fields = slices.Clone(fields)
+	slices.Sort(fields)
+	query := url.Values{"fields": {strings.Join(slices.Compact(fields), ",")}}
+	path := countriesUpstreamPath + key + "?" + query.Encode()
+	return c.lookup(ctx, c.cache, path, path)
+}
+
+// GetAll fetches every country known to the upstream. The list is cached for
+// as long as a country lookup, but apart from them, and shared between
+// concurrent callers like a single country lookup.
+func (c *CountriesClient) GetAll(ctx context.Context) ([]Country, error) {
+	return c.lookup(ctx, c.all, allCountriesKey, countriesAllPath)
+}
+
+// lookup serves path from store under key, or requests it from the upstream
+// once for all concurrent callers.
+func (c *CountriesClient) lookup(ctx context.Context, store *cache.Cache[string, countriesEntry], key, path string) ([]Country, error) {
 	if c.baseURL == "" {
 		return nil, fmt.Errorf("countries endpoint is not configured")
 	}
 
-	url := c.baseURL + countriesUpstreamPath + countryCode
+	if e, ok := store.Get(key); ok {
+		return e.countries, e.err
+	}
+
+	e, err := c.inflight.Do(ctx, key, func(shared context.Context) (countriesEntry, error) {
+		fetchCtx, cancel := bound(shared, ctx, c.retry.budget(c.client.Timeout))
+		defer cancel()
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		countries, err := c.fetch(fetchCtx, c.baseURL+path)
+		switch {
+		case err == nil:
+			store.Set(key, countriesEntry{countries: countries})
+		case errors.Is(err, ErrNotFound):
+			store.SetWithTTL(key, countriesEntry{err: err}, c.notFoundTTL)
+		}
+		return countriesEntry{countries: countries, err: err}, nil
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to build upstream request: %w", err)
+		return nil, err
+	}
+	return e.countries, e.err
+}
+
+// BatchResult is the outcome of a batch country lookup.
+type BatchResult struct {
+	// Countries maps each resolved code, upper-cased as requested, to its country.
+	Countries map[string]Country
+	// Unresolved lists the requested codes the upstream did not know, upper-cased
+	// and in request order.
+	Unresolved []string
+}
+
+// GetByCodes resolves many alpha-2, alpha-3 or numeric country codes with a
+// single upstream request. Codes already in the cache are not requested again,
+// and every code resolved or reported unknown by the upstream is cached
+// individually, so later GetByAlpha calls benefit too.
+func (c *CountriesClient) GetByCodes(ctx context.Context, codes []string) (*BatchResult, error) {
+	if c.baseURL == "" {
+		return nil, fmt.Errorf("countries endpoint is not configured")
+	}
+
+	result := &BatchResult{Countries: make(map[string]Country, len(codes))}
+	var missing []string
+	seen := make(map[string]struct{}, len(codes))
+	for _, code := range codes {
+		key := strings.ToLower(strings.TrimSpace(code))
+		if _, dup := seen[key]; dup || key == "" {
+			continue
+		}
+		seen[key] = struct{}{}
+
+		e, ok := c.cache.Get(key)
+		switch {
+		case !ok:
+			missing = append(missing, key)
+		case e.err != nil || len(e.countries) == 0:
+			result.Unresolved = append(result.Unresolved, strings.ToUpper(key))
+		default:
+			result.Countries[strings.ToUpper(key)] = e.countries[0]
+		}
+	}
+
+	if len(missing) > 0 {
+		slices.Sort(missing)
+		batchKey := countriesBatchPath + "?" + strings.Join(missing, ",")
+		e, err := c.inflight.Do(ctx, batchKey, func(shared context.Context) (countriesEntry, error) {
+			fetchCtx, cancel := bound(shared, ctx, c.retry.budget(c.client.Timeout))
+			defer cancel()
+
+			countries, err := c.fetchByCodes(fetchCtx, missing)
+			return countriesEntry{countries: countries, err: err}, nil
+		})
+		if err == nil {
+			err = e.err
+		}
+		if err != nil && !errors.Is(err, ErrNotFound) {
+			return nil, err
+		}
+
+		byCode := indexByCode(e.countries)
+		for _, key := range missing {
+			country, ok := byCode[key]
+			if !ok {
+				c.cache.SetWithTTL(key, countriesEntry{err: &UpstreamError{
+					Upstream: countriesUpstreamName,
+					Kind:     ErrNotFound,
+				}}, c.notFoundTTL)
+				result.Unresolved = append(result.Unresolved, strings.ToUpper(key))
+				continue
+			}
+			c.cache.Set(key, countriesEntry{countries: []Country{country}})
+			result.Countries[strings.ToUpper(key)] = country
+		}
+	}
+
+	// Restore request order, which cache hits and misses interleave.
+	order := make(map[string]int, len(codes))
+	for i, code := range codes {
+		order[strings.ToUpper(strings.TrimSpace(code))] = i
 	}
+	slices.SortFunc(result.Unresolved, func(a, b string) int { return order[a] - order[b] })
 
-	res, err := c.client.Do(req)
+	return result, nil
+}
+
+// indexByCode maps the lower-cased alpha-2, alpha-3 and numeric codes of each
+// country to the country.
+func indexByCode(countries []Country) map[string]Country {
+	byCode := make(map[string]Country, 3*len(countries))
+	for _, country := range countries {
+		for _, code := range []string{country.CCA2, country.CCA3, country.CCN3} {
+			if code != "" {
+				byCode[strings.ToLower(code)] = country
+			}
+		}
+	}
+	return byCode
+}
+
+// fetchByCodes performs a single upstream request for several country codes.
+func (c *CountriesClient) fetchByCodes(ctx context.Context, codes []string) ([]Country, error) {
+	query := url.Values{"codes": {strings.Join(codes, ",")}}
+	return c.fetch(ctx, c.baseURL+countriesBatchPath+"?"+query.Encode())
+}
+
+// fetch requests endpoint from the countries upstream and decodes the country list.
+func (c *CountriesClient) fetch(ctx context.Context, endpoint string) ([]Country, error) {
+	res, err := guardedGet(ctx, c.breaker, c.retry, c.client, endpoint, countriesUpstreamName)
 	if err != nil {
-		return nil, fmt.Errorf("failed to reach countries endpoint: %w", err)
+		return nil, err
 	}
 	defer res.Body.Close()
 
 	if res.StatusCode < http.StatusOK || res.StatusCode >= http.StatusMultipleChoices {
-		return nil, fmt.Errorf("countries endpoint returned status %d", res.StatusCode)
+		return nil, newStatusError(countriesUpstreamName, res)
 	}
 
 	body, err := io.ReadAll(res.Body)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response body from countries endpoint: %w", err)
+		return nil, newTransportError(countriesUpstreamName, err)
+	}
+
+	// Single-country lookups with ?fields= return an object instead of a list.
+	if trimmed := bytes.TrimSpace(body); len(trimmed) > 0 && trimmed[0] == '{' {
+		var country Country
+		if err := json.Unmarshal(trimmed, &country); err != nil {
+			return nil, newPayloadError(countriesUpstreamName, err)
+		}
+		return []Country{country}, nil
 	}
 
 	var countries []Country
 	if err := json.Unmarshal(body, &countries); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal json: %w", err)
+		return nil, newPayloadError(countriesUpstreamName, err)
 	}
 
 	return countries, nil
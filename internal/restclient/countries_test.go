@@ -0,0 +1,242 @@
+package restclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestGetByAlphaCachesSuccessfulLookups(t *testing.T) {
+	t.Parallel()
+
+	var hits atomic.Int32
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`[{"name":{"common":"Norway"}}]`))
+	}))
+	defer upstream.Close()
+
+	client := NewCountriesClient(upstream.URL + "/v3.1")
+	for range 3 {
+		countries, err := client.GetByAlpha(context.Background(), "NO")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(countries) != 1 || countries[0].Name.Common != "Norway" {
+			t.Fatalf("unexpected countries: %+v", countries)
+		}
+	}
+
+	if got := hits.Load(); got != 1 {
+		t.Fatalf("expected 1 upstream request, got %d", got)
+	}
+	stats := client.CacheStats()
+	if stats.Hits != 2 || stats.Misses != 1 {
+		t.Errorf("expected 2 hits and 1 miss, got %d hits and %d misses", stats.Hits, stats.Misses)
+	}
+}
+
+func TestGetByAlphaCachesNotFound(t *testing.T) {
+	t.Parallel()
+
+	var hits atomic.Int32
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer upstream.Close()
+
+	client := NewCountriesClient(upstream.URL, WithCountriesCache(16, time.Hour, time.Minute))
+	for range 2 {
+		if _, err := client.GetByAlpha(context.Background(), "xx"); err == nil {
+			t.Fatal("expected error for unknown country")
+		}
+	}
+
+	if got := hits.Load(); got != 1 {
+		t.Fatalf("expected 1 upstream request, got %d", got)
+	}
+}
+
+func TestGetByAlphaCoalescesConcurrentLookups(t *testing.T) {
+	t.Parallel()
+
+	var hits atomic.Int32
+	release := make(chan struct{})
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		<-release
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`[{"name":{"common":"Sweden"}}]`))
+	}))
+	defer upstream.Close()
+
+	// Without a cache, only coalescing can keep the lookups to one request.
+	client := NewCountriesClient(upstream.URL, WithCountriesCache(0, 0, 0))
+
+	const callers = 5
+	var wg sync.WaitGroup
+	for range callers {
+		wg.Go(func() {
+			if _, err := client.GetByAlpha(context.Background(), "se"); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+
+	for client.inflight.Waiters("se") < callers {
+		runtime.Gosched()
+	}
+	close(release)
+	wg.Wait()
+
+	if got := hits.Load(); got != 1 {
+		t.Fatalf("expected 1 upstream request, got %d", got)
+	}
+}
+
+func TestGetByCodesResolvesCodesInOneRequest(t *testing.T) {
+	t.Parallel()
+
+	var hits atomic.Int32
+	var gotCodes string
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		gotCodes = r.URL.Query().Get("codes")
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`[
+			{"cca2":"FI","cca3":"FIN","ccn3":"246","name":{"common":"Finland"}},
+			{"cca2":"SE","cca3":"SWE","ccn3":"752","name":{"common":"Sweden"}}
+		]`))
+	}))
+	defer upstream.Close()
+
+	client := NewCountriesClient(upstream.URL)
+	result, err := client.GetByCodes(context.Background(), []string{"SWE", "xxx", "fi", "SWE"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got := hits.Load(); got != 1 {
+		t.Fatalf("expected 1 upstream request, got %d", got)
+	}
+	if gotCodes != "fi,swe,xxx" {
+		t.Errorf("expected codes query fi,swe,xxx, got %q", gotCodes)
+	}
+	if got := result.Countries["SWE"].Name.Common; got != "Sweden" {
+		t.Errorf("expected SWE to resolve to Sweden, got %q", got)
+	}
+	if got := result.Countries["FI"].Name.Common; got != "Finland" {
+		t.Errorf("expected FI to resolve to Finland, got %q", got)
+	}
+	if len(result.Unresolved) != 1 || result.Unresolved[0] != "XXX" {
+		t.Errorf("expected unresolved [XXX], got %v", result.Unresolved)
+	}
+
+	// Every code is now cached individually.
+	if _, err := client.GetByAlpha(context.Background(), "swe"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := client.GetByCodes(context.Background(), []string{"fi", "xxx"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := hits.Load(); got != 1 {
+		t.Fatalf("expected cached lookups to skip the upstream, got %d requests", got)
+	}
+}
+
+func TestGetAllCachesCountryList(t *testing.T) {
+	t.Parallel()
+
+	var hits atomic.Int32
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		if r.URL.Path != "/v3.1/all" {
+			t.Errorf("unexpected upstream path %q", r.URL.Path)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`[{"cca2":"NO","name":{"common":"Norway"}},{"cca2":"SE","name":{"common":"Sweden"}}]`))
+	}))
+	defer upstream.Close()
+
+	client := NewCountriesClient(upstream.URL + "/v3.1")
+	for range 2 {
+		countries, err := client.GetAll(context.Background())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(countries) != 2 {
+			t.Fatalf("expected 2 countries, got %d", len(countries))
+		}
+	}
+	if got := hits.Load(); got != 1 {
+		t.Fatalf("expected 1 upstream request, got %d", got)
+	}
+}
+
+func TestGetAllIsNotEvictedByCodeLookups(t *testing.T) {
+	t.Parallel()
+
+	var allHits atomic.Int32
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v3.1/all" {
+			allHits.Add(1)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`[{"cca2":"NO","name":{"common":"Norway"}}]`))
+	}))
+	defer upstream.Close()
+
+	client := NewCountriesClient(upstream.URL+"/v3.1", WithCountriesCache(2, time.Hour, time.Hour))
+	if _, err := client.GetAll(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// More code lookups than the cache holds.
+	for _, code := range []string{"no", "se", "fi"} {
+		if _, err := client.GetByAlpha(context.Background(), code); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if _, err := client.GetAll(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got := allHits.Load(); got != 1 {
+		t.Fatalf("expected the country list to be fetched once, got %d requests", got)
+	}
+}
+
+func TestGetByAlphaForwardsFields(t *testing.T) {
+	t.Parallel()
+
+	var hits atomic.Int32
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		if got := r.URL.RequestURI(); got != "/alpha/no?fields=capital%2Cname" {
+			t.Errorf("unexpected upstream request %q", got)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"name":{"common":"Norway"},"capital":["Oslo"]}`))
+	}))
+	defer upstream.Close()
+
+	client := NewCountriesClient(upstream.URL)
+	for range 2 {
+		countries, err := client.GetByAlpha(context.Background(), "NO", "name", "capital", "name")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(countries) != 1 || countries[0].Name.Common != "Norway" || countries[0].Capital[0] != "Oslo" {
+			t.Fatalf("unexpected countries: %+v", countries)
+		}
+	}
+	if got := hits.Load(); got != 1 {
+		t.Fatalf("expected 1 upstream request, got %d", got)
+	}
+}
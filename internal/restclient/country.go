@@ -0,0 +1,122 @@
+package restclient
+
+// Country represents the upstream REST Countries API v3.1 response shape.
+type Country struct {
+	Name         Name                   `json:"name"`
+	TLD          []string               `json:"tld"`
+	CCA2         string                 `json:"cca2"`
+	CCN3         string                 `json:"ccn3"`
+	CCA3         string                 `json:"cca3"`
+	CIOC         string                 `json:"cioc"`
+	FIFA         string                 `json:"fifa"`
+	Independent  bool                   `json:"independent"`
+	Status       string                 `json:"status"`
+	UNMember     bool                   `json:"unMember"`
+	Currencies   map[string]Currency    `json:"currencies"`
+	IDD          IDD                    `json:"idd"`
+	Capital      []string               `json:"capital"`
+	CapitalInfo  CapitalInfo            `json:"capitalInfo"`
+	AltSpellings []string               `json:"altSpellings"`
+	Region       string                 `json:"region"`
+	Subregion    string                 `json:"subregion"`
+	Continents   []string               `json:"continents"`
+	Languages    map[string]string      `json:"languages"`
+	Translations map[string]CountryName `json:"translations"`
+	Demonyms     map[string]Demonym     `json:"demonyms"`
+	// LatLng is the latitude and longitude of the country's centre.
+	LatLng     []float64 `json:"latlng"`
+	Landlocked bool      `json:"landlocked"`
+	Borders    []string  `json:"borders"`
+	Area       float64   `json:"area"`
+	Population int       `json:"population"`
+	// Gini maps the survey year to the country's Gini coefficient.
+	Gini        map[string]float64 `json:"gini"`
+	Timezones   []string           `json:"timezones"`
+	Car         Car                `json:"car"`
+	PostalCode  PostalCode         `json:"postalCode"`
+	StartOfWeek string             `json:"startOfWeek"`
+	Maps        Maps               `json:"maps"`
+	Flag        string             `json:"flag"`
+	Flags       Images             `json:"flags"`
+	CoatOfArms  Images             `json:"coatOfArms"`
+}
+
+// Name holds a country's English names and its names in its own languages,
+// keyed by ISO 639-3 language code.
+type Name struct {
+	Common     string                 `json:"common"`
+	Official   string                 `json:"official"`
+	NativeName map[string]CountryName `json:"nativeName"`
+}
+
+// CountryName is a common and official country name in one language.
+type CountryName struct {
+	Common   string `json:"common"`
+	Official string `json:"official"`
+}
+
+// Currency describes one of the currencies a country uses.
+type Currency struct {
+	Name   string `json:"name"`
+	Symbol string `json:"symbol"`
+}
+
+// IDD is the international direct dialling prefix. Each full calling code is
+// the root followed by one of the suffixes, e.g. "+4" and "7" for Norway.
+type IDD struct {
+	Root     string   `json:"root"`
+	Suffixes []string `json:"suffixes"`
+}
+
+// CallingCodes returns the full international calling codes.
+func (i IDD) CallingCodes() []string {
+	if i.Root == "" {
+		return nil
+	}
+	if len(i.Suffixes) == 0 {
+		return []string{i.Root}
+	}
+	codes := make([]string, 0, len(i.Suffixes))
+	for _, suffix := range i.Suffixes {
+		codes = append(codes, i.Root+suffix)
+	}
+	return codes
+}
+
+// CapitalInfo locates the capital.
+type CapitalInfo struct {
+	LatLng []float64 `json:"latlng"`
+}
+
+// Demonym is the name for residents of a country, by gender.
+type Demonym struct {
+	F string `json:"f"`
+	M string `json:"m"`
+}
+
+// Car describes road traffic: the international vehicle registration codes
+// and the side of the road driven on.
+type Car struct {
+	Signs []string `json:"signs"`
+	Side  string   `json:"side"`
+}
+
+// PostalCode describes the format of postal codes, if the country has them.
+type PostalCode struct {
+	Format string `json:"format"`
+	Regex  string `json:"regex"`
+}
+
+// Maps links to the country on online maps.
+type Maps struct {
+	GoogleMaps     string `json:"googleMaps"`
+	OpenStreetMaps string `json:"openStreetMaps"`
+}
+
+// Images links to a picture in PNG and SVG format, with alternative text
+// where the upstream provides it.
+type Images struct {
+	Png string `json:"png"`
+	Svg string `json:"svg"`
+	Alt string `json:"alt"`
+}
@@ -0,0 +1,80 @@
+package restclient
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+)
+
+const norwayV31 = `{
+	"name":{"common":"Norway","official":"Kingdom of Norway","nativeName":{"nno":{"official":"Kongeriket Noreg","common":"Noreg"},"nob":{"official":"Kongeriket Norge","common":"Norge"},"smi":{"official":"Norgga gonagasriika","common":"Norgga"}}},
+	"tld":[".no"],"cca2":"NO","ccn3":"578","cca3":"NOR","cioc":"NOR","independent":true,"status":"officially-assigned","unMember":true,
+	"currencies":{"NOK":{"name":"Norwegian krone","symbol":"kr"}},
+	"idd":{"root":"+4","suffixes":["7"]},
+	"capital":["Oslo"],"altSpellings":["NO","Norge","Noreg","Kingdom of Norway","Kongeriket Norge","Kongeriket Noreg"],
+	"region":"Europe","subregion":"Northern Europe",
+	"languages":{"nno":"Norwegian Nynorsk","nob":"Norwegian Bokmål","smi":"Sami"},
+	"translations":{"deu":{"official":"Königreich Norwegen","common":"Norwegen"},"fra":{"official":"Royaume de Norvège","common":"Norvège"}},
+	"latlng":[62.0,10.0],"landlocked":false,"borders":["FIN","SWE","RUS"],"area":323802.0,
+	"demonyms":{"eng":{"f":"Norwegian","m":"Norwegian"},"fra":{"f":"Norvégienne","m":"Norvégien"}},
+	"flag":"🇳🇴","maps":{"googleMaps":"https://goo.gl/maps/htWRrphA7vNgQNdSA","openStreetMaps":"https://www.openstreetmap.org/relation/2978650"},
+	"population":5379475,"gini":{"2018":27.6},"fifa":"NOR","car":{"signs":["N"],"side":"right"},
+	"timezones":["UTC+01:00"],"continents":["Europe"],
+	"flags":{"png":"https://flagcdn.com/w320/no.png","svg":"https://flagcdn.com/no.svg","alt":"The flag of Norway has a red field with a large white-edged navy blue cross."},
+	"coatOfArms":{"png":"https://mainfacts.com/media/images/coats_of_arms/no.png","svg":"https://mainfacts.com/media/images/coats_of_arms/no.svg"},
+	"startOfWeek":"monday","capitalInfo":{"latlng":[59.92,10.75]},"postalCode":{"format":"####","regex":"^(\\d{4})$"}
+}`
+
+func TestCountryDecodesFullSchema(t *testing.T) {
+	t.Parallel()
+
+	var c Country
+	if err := json.Unmarshal([]byte(norwayV31), &c); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if c.Name.NativeName["nob"].Common != "Norge" || c.Translations["deu"].Common != "Norwegen" {
+		t.Errorf("unexpected names: %+v, %+v", c.Name, c.Translations)
+	}
+	if c.CCA2 != "NO" || c.CCA3 != "NOR" || c.CCN3 != "578" || c.CIOC != "NOR" {
+		t.Errorf("unexpected codes: %s %s %s %s", c.CCA2, c.CCA3, c.CCN3, c.CIOC)
+	}
+	if !slices.Equal(c.IDD.CallingCodes(), []string{"+47"}) {
+		t.Errorf("unexpected calling codes: %v", c.IDD.CallingCodes())
+	}
+	if !slices.Equal(c.LatLng, []float64{62, 10}) || !slices.Equal(c.CapitalInfo.LatLng, []float64{59.92, 10.75}) {
+		t.Errorf("unexpected coordinates: %v, %v", c.LatLng, c.CapitalInfo.LatLng)
+	}
+	if c.Region != "Europe" || c.Subregion != "Northern Europe" || c.Demonyms["eng"].F != "Norwegian" {
+		t.Errorf("unexpected region or demonyms: %s, %s, %+v", c.Region, c.Subregion, c.Demonyms)
+	}
+	if c.Gini["2018"] != 27.6 || c.Car.Side != "right" || c.PostalCode.Format != "####" {
+		t.Errorf("unexpected gini, car or postal code: %v, %+v, %+v", c.Gini, c.Car, c.PostalCode)
+	}
+	if c.Landlocked || !c.Independent || !c.UNMember || c.StartOfWeek != "monday" {
+		t.Errorf("unexpected flags: landlocked=%t independent=%t unMember=%t startOfWeek=%s",
+			c.Landlocked, c.Independent, c.UNMember, c.StartOfWeek)
+	}
+	if c.Maps.OpenStreetMaps == "" || c.CoatOfArms.Svg == "" || c.Currencies["NOK"].Symbol != "kr" {
+		t.Errorf("unexpected maps, coat of arms or currencies: %+v, %+v, %+v", c.Maps, c.CoatOfArms, c.Currencies)
+	}
+}
+
+func TestCallingCodes(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		idd  IDD
+		want []string
+	}{
+		{IDD{}, nil},
+		{IDD{Root: "+4", Suffixes: []string{"7"}}, []string{"+47"}},
+		{IDD{Root: "+1", Suffixes: []string{"201", "202"}}, []string{"+1201", "+1202"}},
+		{IDD{Root: "+7"}, []string{"+7"}},
+	}
+	for _, tt := range tests {
+		if got := tt.idd.CallingCodes(); !slices.Equal(got, tt.want) {
+			t.Errorf("CallingCodes(%+v) = %v, want %v", tt.idd, got, tt.want)
+		}
+	}
+}
@@ -2,74 +2,187 @@ package restclient
 
 import (
 	"context"
+	"countryinfo/internal/cache"
 	"encoding/json"
 	"fmt"
 	"io"
+	"log/slog"
 	"net/http"
 	"strings"
 	"time"
 )
 
 const (
+	currencyUpstreamName    = "currency"
 	currencyUpstreamTimeout = 5 * time.Second
+
+	// Rate tables are small and there are few base currencies in practice,
+	// so the cache is sized generously rather than made configurable.
+	ratesCacheSize = 256
+)
+
+// Defaults of the rates cache, used unless WithRatesCache is given.
+const (
+	DefaultRatesFreshFor = time.Hour
+	DefaultRatesStaleFor = 24 * time.Hour
 )
 
 // CurrencyResponse represents the upstream currency exchange API response.
+// The untagged fields describe where the rates came from and are not part of
+// the upstream payload.
 type CurrencyResponse struct {
 	BaseCode string             `json:"base_code"`
 	Rates    map[string]float64 `json:"rates"`
+
+	FetchedAt time.Time `json:"-"`
+	FromCache bool      `json:"-"`
+	Stale     bool      `json:"-"`
+}
+
+// Age reports how long ago the rates were fetched from the upstream.
+func (r *CurrencyResponse) Age() time.Duration {
+	return time.Since(r.FetchedAt)
 }
 
 // CurrencyClient handles HTTP communication with the currency exchange API.
 type CurrencyClient struct {
-	client  *http.Client
-	baseURL string
+	client   *http.Client
+	baseURL  string
+	cache    *cache.Cache[string, CurrencyResponse]
+	freshFor time.Duration
+	inflight cache.Group[string, *CurrencyResponse]
+	retry    RetryPolicy
+	breaker  *Breaker
+	now      func() time.Time
+}
+
+// CurrencyOption configures optional CurrencyClient behaviour.
+type CurrencyOption func(*CurrencyClient)
+
+// WithRatesCache sets how long a rate table is served without revalidation
+// (freshFor) and how much longer it may be served, labelled stale, when the
+// upstream cannot be reached (staleFor).
+func WithRatesCache(freshFor, staleFor time.Duration) CurrencyOption {
+	return func(c *CurrencyClient) {
+		c.cache = cache.New[string, CurrencyResponse](ratesCacheSize, freshFor+staleFor)
+		c.freshFor = freshFor
+	}
+}
+
+// WithCurrencyRetry sets the retry policy for requests to the currency upstream.
+func WithCurrencyRetry(policy RetryPolicy) CurrencyOption {
+	return func(c *CurrencyClient) {
+		c.retry = policy
+	}
+}
+
+// WithCurrencyBreaker sets the circuit breaker settings for the currency upstream.
+func WithCurrencyBreaker(settings BreakerSettings) CurrencyOption {
+	return func(c *CurrencyClient) {
+		c.breaker = NewBreaker(currencyUpstreamName, settings)
+	}
 }
 
 // NewCurrencyClient creates a CurrencyClient for the given base URL.
 // The base URL should point to the currency service, e.g. "http://129.241.150.113:9090/currency".
-func NewCurrencyClient(baseURL string) *CurrencyClient {
+func NewCurrencyClient(baseURL string, opts ...CurrencyOption) *CurrencyClient {
 	cleaned := strings.TrimSpace(baseURL)
 	if cleaned != "" {
 		cleaned = strings.TrimRight(cleaned, "/") + "/"
 	}
-	return &CurrencyClient{
-		client:  &http.Client{Timeout: currencyUpstreamTimeout},
-		baseURL: cleaned,
+	c := &CurrencyClient{
+		client:   &http.Client{Timeout: currencyUpstreamTimeout},
+		baseURL:  cleaned,
+		cache:    cache.New[string, CurrencyResponse](ratesCacheSize, DefaultRatesFreshFor+DefaultRatesStaleFor),
+		freshFor: DefaultRatesFreshFor,
+		retry:    DefaultRetryPolicy,
+		breaker:  NewBreaker(currencyUpstreamName, DefaultBreakerSettings),
+		now:      time.Now,
+	}
+	for _, opt := range opts {
+		opt(c)
 	}
+	return c
+}
+
+// CacheStats reports hit, miss and eviction counters for the rate cache.
+func (c *CurrencyClient) CacheStats() cache.Stats {
+	return c.cache.Stats()
+}
+
+// Breaker returns the circuit breaker guarding the currency upstream.
+func (c *CurrencyClient) Breaker() *Breaker {
+	return c.breaker
 }
 
 // GetExchangeRates fetches exchange rates for the given 3-letter currency code (ISO 4217).
+// Fresh cached tables are returned without contacting the upstream. If the
+// upstream fails and a cached table is still within its stale window, that
+// table is returned with Stale set instead of an error.
 func (c *CurrencyClient) GetExchangeRates(ctx context.Context, currencyCode string) (*CurrencyResponse, error) {
 	if c.baseURL == "" {
 		return nil, fmt.Errorf("currency endpoint is not configured")
 	}
 
-	url := c.baseURL + strings.ToUpper(currencyCode)
+	key := strings.ToUpper(currencyCode)
+	cached, ok := c.cache.Get(key)
+	if ok && c.now().Sub(cached.FetchedAt) < c.freshFor {
+		cached.FromCache = true
+		return &cached, nil
+	}
+
+	response, err := c.inflight.Do(ctx, key, func(shared context.Context) (*CurrencyResponse, error) {
+		fetchCtx, cancel := bound(shared, ctx, c.retry.budget(c.client.Timeout))
+		defer cancel()
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		response, err := c.fetchExchangeRates(fetchCtx, key)
+		if err != nil {
+			return nil, err
+		}
+		response.FetchedAt = c.now()
+		c.cache.Set(key, *response)
+		return response, nil
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to build upstream request: %w", err)
+		if ok {
+			slog.WarnContext(ctx, "serving stale exchange rates",
+				"error", err,
+				"base_currency", key,
+				"fetched_at", cached.FetchedAt,
+			)
+			cached.FromCache = true
+			cached.Stale = true
+			return &cached, nil
+		}
+		return nil, err
 	}
 
-	res, err := c.client.Do(req)
+	// Hand each caller its own copy; the result may be shared by coalesced callers.
+	out := *response
+	return &out, nil
+}
+
+func (c *CurrencyClient) fetchExchangeRates(ctx context.Context, currencyCode string) (*CurrencyResponse, error) {
+	url := c.baseURL + currencyCode
+
+	res, err := guardedGet(ctx, c.breaker, c.retry, c.client, url, currencyUpstreamName)
 	if err != nil {
-		return nil, fmt.Errorf("failed to reach currency endpoint: %w", err)
+		return nil, err
 	}
 	defer res.Body.Close()
 
 	if res.StatusCode < http.StatusOK || res.StatusCode >= http.StatusMultipleChoices {
-		return nil, fmt.Errorf("currency endpoint returned status %d", res.StatusCode)
+		return nil, newStatusError(currencyUpstreamName, res)
 	}
 
 	body, err := io.ReadAll(res.Body)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response body from currency endpoint: %w", err)
+		return nil, newTransportError(currencyUpstreamName, err)
 	}
 
 	var response CurrencyResponse
 	if err := json.Unmarshal(body, &response); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal json: %w", err)
+		return nil, newPayloadError(currencyUpstreamName, err)
 	}
 
 	return &response, nil
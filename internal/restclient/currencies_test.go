@@ -0,0 +1,84 @@
+package restclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestGetExchangeRatesServesFreshTablesFromCache(t *testing.T) {
+	t.Parallel()
+
+	var hits atomic.Int32
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"base_code":"NOK","rates":{"EUR":0.086536}}`))
+	}))
+	defer upstream.Close()
+
+	client := NewCurrencyClient(upstream.URL, WithRatesCache(time.Hour, time.Hour))
+
+	first, err := client.GetExchangeRates(context.Background(), "nok")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if first.FromCache {
+		t.Error("expected first lookup to come from the upstream")
+	}
+
+	second, err := client.GetExchangeRates(context.Background(), "NOK")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !second.FromCache || second.Stale {
+		t.Errorf("expected fresh cached rates, got cached=%v stale=%v", second.FromCache, second.Stale)
+	}
+	if got := hits.Load(); got != 1 {
+		t.Fatalf("expected 1 upstream request, got %d", got)
+	}
+}
+
+func TestGetExchangeRatesServesStaleTablesWhenUpstreamFails(t *testing.T) {
+	t.Parallel()
+
+	var down atomic.Bool
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if down.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"base_code":"NOK","rates":{"EUR":0.086536}}`))
+	}))
+	defer upstream.Close()
+
+	now := time.Now()
+	client := NewCurrencyClient(
+		upstream.URL,
+		WithRatesCache(time.Minute, time.Hour),
+		WithCurrencyRetry(RetryPolicy{MaxAttempts: 1}),
+	)
+	client.now = func() time.Time { return now }
+
+	if _, err := client.GetExchangeRates(context.Background(), "NOK"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	down.Store(true)
+	now = now.Add(5 * time.Minute)
+
+	rates, err := client.GetExchangeRates(context.Background(), "NOK")
+	if err != nil {
+		t.Fatalf("expected stale rates, got error: %v", err)
+	}
+	if !rates.Stale || !rates.FromCache {
+		t.Errorf("expected stale cached rates, got cached=%v stale=%v", rates.FromCache, rates.Stale)
+	}
+	if rates.Rates["EUR"] != 0.086536 {
+		t.Errorf("expected EUR rate 0.086536, got %f", rates.Rates["EUR"])
+	}
+}
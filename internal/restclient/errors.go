@@ -0,0 +1,166 @@
+package restclient
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxBodySnippet bounds how much of an error response body is kept for logging.
+const maxBodySnippet = 512
+
+// Error kinds for failed upstream requests. Match them with errors.Is; use
+// errors.As with *UpstreamError to get at the status code and body.
+var (
+	ErrNotFound         = errors.New("not found")
+	ErrRateLimited      = errors.New("rate limited")
+	ErrUnavailable      = errors.New("unavailable")
+	ErrTimeout          = errors.New("timed out")
+	ErrMalformedPayload = errors.New("malformed payload")
+)
+
+// UpstreamError describes a failed request to an upstream API.
+type UpstreamError struct {
+	// Upstream names the API that failed, e.g. "countries".
+	Upstream string
+	// Kind is one of the Err* kinds above, or nil if the failure fits none of them.
+	Kind error
+	// StatusCode is the upstream HTTP status, or 0 if no response was received.
+	StatusCode int
+	// Body is the start of the upstream response body, for logging.
+	Body string
+	// Err is the underlying cause, if any.
+	Err error
+}
+
+func (e *UpstreamError) Error() string {
+	var b strings.Builder
+	b.WriteString(e.Upstream)
+	b.WriteString(" endpoint")
+	if e.StatusCode != 0 {
+		b.WriteString(" returned status ")
+		b.WriteString(strconv.Itoa(e.StatusCode))
+	}
+	if e.Kind != nil {
+		b.WriteString(": ")
+		b.WriteString(e.Kind.Error())
+	}
+	if e.Err != nil {
+		b.WriteString(": ")
+		b.WriteString(e.Err.Error())
+	}
+	return b.String()
+}
+
+func (e *UpstreamError) Unwrap() []error {
+	errs := make([]error, 0, 2)
+	if e.Kind != nil {
+		errs = append(errs, e.Kind)
+	}
+	if e.Err != nil {
+		errs = append(errs, e.Err)
+	}
+	return errs
+}
+
+// LogValue exposes the upstream status and body snippet as structured log attributes.
+func (e *UpstreamError) LogValue() slog.Value {
+	attrs := []slog.Attr{
+		slog.String("message", e.Error()),
+		slog.String("upstream", e.Upstream),
+	}
+	if e.StatusCode != 0 {
+		attrs = append(attrs, slog.Int("status", e.StatusCode))
+	}
+	if e.Body != "" {
+		attrs = append(attrs, slog.String("body", e.Body))
+	}
+	return slog.GroupValue(attrs...)
+}
+
+// HTTPStatus maps an error returned by the upstream clients to the status code
+// a handler should answer with: 404, 429, 503 or 504 for the matching kinds and
+// 502 for anything else.
+func HTTPStatus(err error) int {
+	switch {
+	case errors.Is(err, ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, ErrRateLimited):
+		return http.StatusTooManyRequests
+	case errors.Is(err, ErrUnavailable):
+		return http.StatusServiceUnavailable
+	case errors.Is(err, ErrTimeout):
+		return http.StatusGatewayTimeout
+	default:
+		return http.StatusBadGateway
+	}
+}
+
+// ErrorMessage returns a short client-facing description of an upstream error.
+func ErrorMessage(err error) string {
+	upstream := "upstream"
+	var upstreamErr *UpstreamError
+	if errors.As(err, &upstreamErr) {
+		upstream = upstreamErr.Upstream
+	}
+
+	switch {
+	case errors.Is(err, ErrNotFound):
+		return "not found"
+	case errors.Is(err, ErrRateLimited):
+		return upstream + " endpoint is rate limiting requests"
+	case errors.Is(err, ErrUnavailable):
+		return upstream + " service temporarily unavailable"
+	case errors.Is(err, ErrTimeout):
+		return upstream + " endpoint timed out"
+	case errors.Is(err, ErrMalformedPayload):
+		return upstream + " endpoint returned a malformed response"
+	default:
+		return "failed to reach " + upstream + " endpoint"
+	}
+}
+
+// newTransportError classifies a failure to get any response from the upstream.
+func newTransportError(upstream string, err error) *UpstreamError {
+	kind := ErrUnavailable
+	var netErr net.Error
+	if errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout()) {
+		kind = ErrTimeout
+	}
+	return &UpstreamError{Upstream: upstream, Kind: kind, Err: err}
+}
+
+// newStatusError classifies a non-2xx upstream response, keeping the start of
+// its body. It does not close the body.
+func newStatusError(upstream string, res *http.Response) *UpstreamError {
+	var kind error
+	switch {
+	case res.StatusCode == http.StatusNotFound:
+		kind = ErrNotFound
+	case res.StatusCode == http.StatusTooManyRequests:
+		kind = ErrRateLimited
+	case res.StatusCode == http.StatusGatewayTimeout:
+		kind = ErrTimeout
+	case res.StatusCode >= http.StatusInternalServerError:
+		kind = ErrUnavailable
+	}
+
+	snippet, _ := io.ReadAll(io.LimitReader(res.Body, maxBodySnippet))
+	return &UpstreamError{
+		Upstream:   upstream,
+		Kind:       kind,
+		StatusCode: res.StatusCode,
+		Body:       strings.ToValidUTF8(string(snippet), string(utf8.RuneError)),
+	}
+}
+
+// newPayloadError reports an upstream response that could not be decoded.
+func newPayloadError(upstream string, err error) *UpstreamError {
+	return &UpstreamError{Upstream: upstream, Kind: ErrMalformedPayload, Err: err}
+}
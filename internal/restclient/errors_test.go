@@ -0,0 +1,70 @@
+package restclient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGetByAlphaClassifiesUpstreamErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     int
+		body       string
+		wantKind   error
+		wantStatus int
+	}{
+		{"not found", http.StatusNotFound, `{"status":404,"message":"Not Found"}`, ErrNotFound, http.StatusNotFound},
+		{"rate limited", http.StatusTooManyRequests, "slow down", ErrRateLimited, http.StatusTooManyRequests},
+		{"unavailable", http.StatusServiceUnavailable, "maintenance", ErrUnavailable, http.StatusServiceUnavailable},
+		{"gateway timeout", http.StatusGatewayTimeout, "", ErrTimeout, http.StatusGatewayTimeout},
+		{"malformed payload", http.StatusOK, "<html>", ErrMalformedPayload, http.StatusBadGateway},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer upstream.Close()
+
+			client := NewCountriesClient(upstream.URL, WithCountriesRetry(RetryPolicy{MaxAttempts: 1}))
+			_, err := client.GetByAlpha(context.Background(), "xx")
+			if !errors.Is(err, tt.wantKind) {
+				t.Fatalf("expected %v, got %v", tt.wantKind, err)
+			}
+			if got := HTTPStatus(err); got != tt.wantStatus {
+				t.Errorf("HTTPStatus() = %d, want %d", got, tt.wantStatus)
+			}
+
+			var upstreamErr *UpstreamError
+			if !errors.As(err, &upstreamErr) {
+				t.Fatalf("expected *UpstreamError, got %T", err)
+			}
+			if upstreamErr.Upstream != countriesUpstreamName {
+				t.Errorf("expected upstream %q, got %q", countriesUpstreamName, upstreamErr.Upstream)
+			}
+			if tt.status != http.StatusOK && (upstreamErr.StatusCode != tt.status || upstreamErr.Body != tt.body) {
+				t.Errorf("expected status %d and body %q, got %d and %q", tt.status, tt.body, upstreamErr.StatusCode, upstreamErr.Body)
+			}
+		})
+	}
+}
+
+func TestGetByAlphaClassifiesConnectionFailures(t *testing.T) {
+	t.Parallel()
+
+	upstream := httptest.NewServer(http.NotFoundHandler())
+	url := upstream.URL
+	upstream.Close()
+
+	client := NewCountriesClient(url, WithCountriesRetry(RetryPolicy{MaxAttempts: 1}))
+	_, err := client.GetByAlpha(context.Background(), "no")
+	if !errors.Is(err, ErrUnavailable) {
+		t.Fatalf("expected ErrUnavailable, got %v", err)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"net/http"
 	"strings"
+	"time"
 )
 
 // Probe performs a lightweight HTTP GET against url and returns the status code.
@@ -32,3 +33,38 @@ func Probe(ctx context.Context, client *http.Client, url string, name string) (i
 
 	return res.StatusCode, nil
 }
+
+// bound gives shared, the context of an upstream call shared between
+// callers, the deadline of ctx, the caller that started the call, so no retry
+// is attempted that this caller could not wait for. If ctx has no deadline,
+// the call expires after budget.
+func bound(shared, ctx context.Context, budget time.Duration) (context.Context, context.CancelFunc) {
+	if deadline, ok := ctx.Deadline(); ok {
+		return context.WithDeadline(shared, deadline)
+	}
+	return context.WithTimeout(shared, budget)
+}
+
+// guardedGet sends a GET request to url through the upstream's circuit breaker
+// and retry policy, classifying transport failures as *UpstreamError.
+// Connection errors and 5xx responses count as breaker
+// failures; requests abandoned by the caller do not count either way.
+func guardedGet(ctx context.Context, breaker *Breaker, retry RetryPolicy, client *http.Client, url string, upstream string) (*http.Response, error) {
+	if err := breaker.Allow(); err != nil {
+		return nil, &UpstreamError{Upstream: upstream, Kind: ErrUnavailable, Err: err}
+	}
+
+	res, err := retry.get(ctx, client, url, upstream)
+	switch {
+	case err != nil && ctx.Err() != nil:
+		breaker.Abandon()
+	case err != nil || res.StatusCode >= http.StatusInternalServerError:
+		breaker.Failure()
+	default:
+		breaker.Success()
+	}
+	if err != nil {
+		return nil, newTransportError(upstream, err)
+	}
+	return res, nil
+}
@@ -0,0 +1,61 @@
+// Package restclienttest provides a mock REST Countries API for tests of the
+// packages built on restclient.
+package restclienttest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// NewCountriesAPI starts a mock REST Countries API under /v3.1 serving the
+// given country JSON objects from /all, by alpha-2, alpha-3 or numeric code
+// from /alpha/{code}, and from /alpha?codes=. Unknown codes get a 404.
+func NewCountriesAPI(t testing.TB, countries []string) *httptest.Server {
+	t.Helper()
+
+	byCode := make(map[string]string)
+	for _, raw := range countries {
+		var codes struct {
+			CCA2 string `json:"cca2"`
+			CCA3 string `json:"cca3"`
+			CCN3 string `json:"ccn3"`
+		}
+		if err := json.Unmarshal([]byte(raw), &codes); err != nil {
+			t.Fatalf("invalid mock country: %v", err)
+		}
+		for _, code := range []string{codes.CCA2, codes.CCA3, codes.CCN3} {
+			if code != "" {
+				byCode[strings.ToLower(code)] = raw
+			}
+		}
+	}
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var matches []string
+		switch path := r.URL.Path; {
+		case path == "/v3.1/all":
+			matches = countries
+		case path == "/v3.1/alpha":
+			for _, code := range strings.Split(r.URL.Query().Get("codes"), ",") {
+				if raw, ok := byCode[strings.ToLower(code)]; ok {
+					matches = append(matches, raw)
+				}
+			}
+		default:
+			if raw, ok := byCode[strings.ToLower(strings.TrimPrefix(path, "/v3.1/alpha/"))]; ok {
+				matches = append(matches, raw)
+			}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if len(matches) == 0 {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"status":404,"message":"Not Found"}`))
+			return
+		}
+		_, _ = w.Write([]byte("[" + strings.Join(matches, ",") + "]"))
+	}))
+}
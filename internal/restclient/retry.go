@@ -0,0 +1,143 @@
+package restclient
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	// maxDrainBytes bounds how much of a discarded response body is read so
+	// the underlying connection can be reused.
+	maxDrainBytes = 64 << 10
+)
+
+// RetryPolicy controls how idempotent upstream GET requests are retried.
+// Connection errors, 429 and 5xx responses are retried with exponential
+// backoff and jitter, honouring Retry-After when the upstream sends it and it
+// is no longer than MaxDelay.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, including the first one.
+	// Values below 1 are treated as 1 (no retries).
+	MaxAttempts int
+	// BaseDelay is the backoff before the second attempt; it doubles for
+	// every further attempt.
+	BaseDelay time.Duration
+	// MaxDelay caps the backoff between two attempts. A longer Retry-After
+	// ends the retries instead.
+	MaxDelay time.Duration
+}
+
+// DefaultRetryPolicy is used by clients that are not given an explicit policy.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 3,
+	BaseDelay:   100 * time.Millisecond,
+	MaxDelay:    2 * time.Second,
+}
+
+// get issues a GET request to url, retrying according to the policy. The
+// final response is returned as-is, whatever its status code. No retry is
+// attempted whose backoff would end after ctx's deadline or whose Retry-After
+// exceeds MaxDelay.
+func (p RetryPolicy) get(ctx context.Context, client *http.Client, url string, upstream string) (*http.Response, error) {
+	attempts := max(p.MaxAttempts, 1)
+	for attempt := 1; ; attempt++ {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		res, err := client.Do(req)
+		if attempt >= attempts || !p.shouldRetry(ctx, res, err) {
+			return res, err
+		}
+
+		delay := p.backoff(attempt)
+		if after, ok := retryAfter(res, time.Now()); ok {
+			if after > p.MaxDelay {
+				return res, err
+			}
+			delay = after
+		}
+		if deadline, ok := ctx.Deadline(); ok && time.Now().Add(delay).After(deadline) {
+			return res, err
+		}
+
+		status := 0
+		if res != nil {
+			status = res.StatusCode
+			_, _ = io.CopyN(io.Discard, res.Body, maxDrainBytes)
+			_ = res.Body.Close()
+		}
+		slog.WarnContext(ctx, "retrying upstream request",
+			"upstream", upstream,
+			"attempt", attempt,
+			"status", status,
+			"error", err,
+			"delay_ms", delay.Milliseconds(),
+		)
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// budget returns the longest a request can take under the policy when every
+// attempt runs for timeout: all attempts plus the longest backoff between them.
+func (p RetryPolicy) budget(timeout time.Duration) time.Duration {
+	attempts := max(p.MaxAttempts, 1)
+	return time.Duration(attempts)*timeout + time.Duration(attempts-1)*p.MaxDelay
+}
+
+func (p RetryPolicy) shouldRetry(ctx context.Context, res *http.Response, err error) bool {
+	if err != nil {
+		// The caller gave up; retrying cannot help.
+		return ctx.Err() == nil
+	}
+	return res.StatusCode == http.StatusTooManyRequests || res.StatusCode >= http.StatusInternalServerError
+}
+
+// backoff returns the jittered delay before attempt+1. The delay is drawn
+// uniformly from the upper half of the exponential step so that concurrent
+// clients spread out without collapsing to near-zero waits.
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	d := p.MaxDelay
+	if shift := attempt - 1; shift < 32 {
+		if step := p.BaseDelay << shift; step > 0 && step < d {
+			d = step
+		}
+	}
+	if d <= 0 {
+		return 0
+	}
+	half := d / 2
+	return half + rand.N(d-half+1)
+}
+
+// retryAfter parses the Retry-After header of res, which may be either a
+// number of seconds or an HTTP date.
+func retryAfter(res *http.Response, now time.Time) (time.Duration, bool) {
+	if res == nil {
+		return 0, false
+	}
+	header := res.Header.Get("Retry-After")
+	if header == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(header); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if at, err := http.ParseTime(header); err == nil {
+		return max(at.Sub(now), 0), true
+	}
+	return 0, false
+}
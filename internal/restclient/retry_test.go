@@ -0,0 +1,154 @@
+package restclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRetryPolicyRetriesServerErrors(t *testing.T) {
+	t.Parallel()
+
+	var hits atomic.Int32
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if hits.Add(1) < 3 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer upstream.Close()
+
+	policy := RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: 5 * time.Millisecond}
+	res, err := policy.get(context.Background(), upstream.Client(), upstream.URL, "test")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", res.StatusCode)
+	}
+	if got := hits.Load(); got != 3 {
+		t.Fatalf("expected 3 attempts, got %d", got)
+	}
+}
+
+func TestRetryPolicyDoesNotRetryClientErrors(t *testing.T) {
+	t.Parallel()
+
+	var hits atomic.Int32
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer upstream.Close()
+
+	policy := RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: 5 * time.Millisecond}
+	res, err := policy.get(context.Background(), upstream.Client(), upstream.URL, "test")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer res.Body.Close()
+
+	if got := hits.Load(); got != 1 {
+		t.Fatalf("expected 1 attempt, got %d", got)
+	}
+}
+
+func TestRetryPolicyStopsAtContextDeadline(t *testing.T) {
+	t.Parallel()
+
+	var hits atomic.Int32
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.Header().Set("Retry-After", "60")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer upstream.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	policy := RetryPolicy{MaxAttempts: 5, BaseDelay: time.Millisecond, MaxDelay: 2 * time.Minute}
+	res, err := policy.get(ctx, upstream.Client(), upstream.URL, "test")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusTooManyRequests {
+		t.Fatalf("expected status 429, got %d", res.StatusCode)
+	}
+	if got := hits.Load(); got != 1 {
+		t.Fatalf("expected no retry past the deadline, got %d attempts", got)
+	}
+}
+
+func TestRetryPolicyGivesUpOnRetryAfterBeyondMaxDelay(t *testing.T) {
+	t.Parallel()
+
+	var hits atomic.Int32
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.Header().Set("Retry-After", "3600")
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer upstream.Close()
+
+	policy := RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: 5 * time.Millisecond}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		res, err := policy.get(context.Background(), upstream.Client(), upstream.URL, "test")
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+			return
+		}
+		defer res.Body.Close()
+		if res.StatusCode != http.StatusServiceUnavailable {
+			t.Errorf("expected status 503, got %d", res.StatusCode)
+		}
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected the retry to be abandoned instead of sleeping")
+	}
+	if got := hits.Load(); got != 1 {
+		t.Fatalf("expected 1 attempt, got %d", got)
+	}
+}
+
+func TestBoundAppliesBudgetWithoutDeadline(t *testing.T) {
+	t.Parallel()
+
+	bounded, release := bound(context.Background(), context.Background(), time.Minute)
+	defer release()
+
+	deadline, ok := bounded.Deadline()
+	if !ok {
+		t.Fatal("expected the bounded context to have a deadline")
+	}
+	if remaining := time.Until(deadline); remaining <= 0 || remaining > time.Minute {
+		t.Errorf("expected a deadline within the budget, got %v", remaining)
+	}
+
+	caller, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	bounded, release = bound(context.Background(), caller, time.Minute)
+	defer release()
+	want, _ := caller.Deadline()
+	if got, _ := bounded.Deadline(); !got.Equal(want) {
+		t.Errorf("expected the caller's deadline, got %v", got)
+	}
+
+	policy := RetryPolicy{MaxAttempts: 3, MaxDelay: 2 * time.Second}
+	if got, want := policy.budget(5*time.Second), 19*time.Second; got != want {
+		t.Errorf("expected budget %v, got %v", want, got)
+	}
+}
@@ -1,21 +1,88 @@
 package router
 
 import (
+	"countryinfo/internal/borders"
 	"countryinfo/internal/config"
+	"countryinfo/internal/handler/capitals"
+	"countryinfo/internal/handler/convert"
 	"countryinfo/internal/handler/exchange"
+	"countryinfo/internal/handler/graph"
 	"countryinfo/internal/handler/info"
+	"countryinfo/internal/handler/neighbours"
+	"countryinfo/internal/handler/route"
+	"countryinfo/internal/handler/search"
 	"countryinfo/internal/handler/status"
+	"countryinfo/internal/resolver"
 	"countryinfo/internal/restclient"
 	"net/http"
 )
 
 func New(cfg *config.Config) http.Handler {
-	countriesClient := restclient.NewCountriesClient(cfg.CountriesEndpoint)
-	currencyClient := restclient.NewCurrencyClient(cfg.CurrencyEndpoint)
+	countriesClient := restclient.NewCountriesClient(
+		cfg.CountriesEndpoint,
+		restclient.WithCountriesCache(cfg.CountriesCacheSize, cfg.CountriesCacheTTL, cfg.CountriesNotFoundTTL),
+		restclient.WithCountriesRetry(retryPolicy(cfg.CountriesRetry)),
+		restclient.WithCountriesBreaker(breakerSettings(cfg)),
+	)
+	currencyClient := restclient.NewCurrencyClient(
+		cfg.CurrencyEndpoint,
+		restclient.WithRatesCache(cfg.RatesCacheTTL, cfg.RatesStaleTTL),
+		restclient.WithCurrencyRetry(retryPolicy(cfg.CurrencyRetry)),
+		restclient.WithCurrencyBreaker(breakerSettings(cfg)),
+	)
+
+	// Handlers share one resolver and one border graph, so the name index
+	// and the graph are built once per country list.
+	countryResolver := resolver.New(countriesClient)
+	borderGraph := borders.NewLoader(countriesClient)
 
 	mux := http.NewServeMux()
-	mux.HandleFunc("GET /countryinfo/v1/status", status.Handler(cfg))
-	mux.HandleFunc("GET /countryinfo/v1/info/{country_code}", info.Handler(countriesClient))
-	mux.HandleFunc("GET /countryinfo/v1/exchange/{country_code}", exchange.Handler(countriesClient, currencyClient))
+	mux.HandleFunc("GET /countryinfo/v1/status", status.Handler(cfg, countriesClient, currencyClient))
+	mux.HandleFunc("GET /countryinfo/v1/info/{country_code}", info.Handler(countryResolver))
+	mux.HandleFunc("GET /countryinfo/v1/exchange/{country_code}", exchange.Handler(
+		countriesClient,
+		currencyClient,
+		countryResolver,
+		borderGraph,
+		exchange.WithConcurrency(cfg.NeighbourConcurrency),
+	))
+	mux.HandleFunc("GET /countryinfo/v1/neighbours/{country_code}", neighbours.Handler(
+		countriesClient,
+		countryResolver,
+		borderGraph,
+		neighbours.WithConcurrency(cfg.NeighbourConcurrency),
+	))
+	mux.HandleFunc("GET /countryinfo/v1/route/{from}/{to}", route.Handler(countryResolver, borderGraph))
+	mux.HandleFunc("GET /countryinfo/v1/sea/{country_code}", route.SeaHandler(countryResolver, borderGraph))
+	mux.HandleFunc("GET /countryinfo/v1/graph", graph.Handler(borderGraph))
+	mux.HandleFunc("GET /countryinfo/v1/graph/landmasses", graph.LandmassesHandler(borderGraph))
+	mux.HandleFunc("GET /countryinfo/v1/graph/enclaves", graph.EnclavesHandler(borderGraph))
+	mux.HandleFunc("GET /countryinfo/v1/graph/rankings", graph.RankingsHandler(borderGraph))
+	mux.HandleFunc("GET /countryinfo/v1/graph/asymmetries", graph.AsymmetriesHandler(borderGraph))
+	mux.HandleFunc("GET /countryinfo/v1/search", search.Handler(countryResolver))
+	mux.HandleFunc("GET /countryinfo/v1/capitals", capitals.Handler(countriesClient))
+	mux.HandleFunc("GET /countryinfo/v1/convert", convert.Handler(currencyClient, cfg.ConvertPivots))
+	mux.HandleFunc("GET /countryinfo/v2/exchange/{country_code}", exchange.HandlerV2(
+		countriesClient,
+		currencyClient,
+		countryResolver,
+		borderGraph,
+		exchange.WithConcurrency(cfg.NeighbourConcurrency),
+	))
 	return mux
 }
+
+func retryPolicy(r config.Retry) restclient.RetryPolicy {
+	return restclient.RetryPolicy{
+		MaxAttempts: r.MaxAttempts,
+		BaseDelay:   r.BaseDelay,
+		MaxDelay:    r.MaxDelay,
+	}
+}
+
+func breakerSettings(cfg *config.Config) restclient.BreakerSettings {
+	return restclient.BreakerSettings{
+		FailureThreshold: cfg.BreakerFailureThreshold,
+		OpenTimeout:      cfg.BreakerOpenTimeout,
+	}
+}
@@ -2,14 +2,27 @@ package util
 
 import (
 	"countryinfo/internal/fp"
+	"fmt"
+	"math"
+	"strconv"
 )
 
 func IsAsciiChar(char rune) bool {
 	return (char >= 'a' && char <= 'z') || (char >= 'A' && char <= 'Z')
 }
 
-func IsTwoLetterCountryCode(countryCode string) bool {
-	return len(countryCode) == 2 && fp.ForAll([]rune(countryCode), func(r rune) bool {
+func IsCurrencyCode(currencyCode string) bool {
+	return len(currencyCode) == 3 && fp.ForAll([]rune(currencyCode), func(r rune) bool {
 		return IsAsciiChar(r)
 	})
 }
+
+// ParseAmount parses a monetary amount given as a query parameter, rejecting
+// values that are not finite numbers.
+func ParseAmount(raw string) (float64, error) {
+	amount, err := strconv.ParseFloat(raw, 64)
+	if err != nil || math.IsNaN(amount) || math.IsInf(amount, 0) {
+		return 0, fmt.Errorf("invalid amount: %s", raw)
+	}
+	return amount, nil
+}
@@ -29,21 +29,22 @@ func TestIsAsciiChar(t *testing.T) {
 	}
 }
 
-func TestIsTwoLetterCountryCode(t *testing.T) {
+func TestIsCurrencyCode(t *testing.T) {
 	tests := []struct {
 		name string
 		args string
 		want bool
 	}{
-		{"no is valid country code", "no", true},
-		{"nok is invalid country code", "nok", false},
-		{"us is valid country code", "us", true},
-		{"c is invlaid country code", "c", false},
+		{"NOK is valid currency code", "NOK", true},
+		{"usd is valid currency code", "usd", true},
+		{"no is invalid currency code", "no", false},
+		{"EURO is invalid currency code", "EURO", false},
+		{"digits are invalid currency code", "978", false},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := IsTwoLetterCountryCode(tt.args); got != tt.want {
-				t.Errorf("IsTwoLetterCountryCode() = %v, want %v", got, tt.want)
+			if got := IsCurrencyCode(tt.args); got != tt.want {
+				t.Errorf("IsCurrencyCode() = %v, want %v", got, tt.want)
 			}
 		})
 	}